@@ -3,99 +3,281 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"test-task/order-service/internal/cache"
 	"test-task/order-service/internal/config"
+	"test-task/order-service/internal/deadletter"
 	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/health"
+	dllist "test-task/order-service/internal/http-server/handlers/deadletter/list"
+	"test-task/order-service/internal/http-server/handlers/deadletter/replay"
+	healthhttp "test-task/order-service/internal/http-server/handlers/health"
+	"test-task/order-service/internal/http-server/handlers/order/create"
 	"test-task/order-service/internal/http-server/handlers/order/get"
+	"test-task/order-service/internal/http-server/handlers/order/history"
+	"test-task/order-service/internal/http-server/handlers/order/list"
 	logger "test-task/order-service/internal/http-server/middleware"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/nats-streaming/publisher"
 	"test-task/order-service/internal/nats-streaming/subscriber"
 	"test-task/order-service/internal/service"
 	"test-task/order-service/internal/storage/postgres"
 	"test-task/order-service/internal/utils"
+	"test-task/order-service/internal/warmup"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/nats-io/nats.go"
-	"github.com/nats-io/stan.go"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// setup logger [dev] -- debug
-	log := log.Default()
+	// logging with defaults until the config is loaded
+	log := logging.New("", "")
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(ctx, log, os.Args[2:]); err != nil {
+			fatal(log, "migration failed", err)
+		}
+		return
+	}
 
 	// init config
 	config, err := config.New()
 	if err != nil {
-		log.Fatal("Error: failed initializing config: ", err)
+		fatal(log, "failed initializing config", err)
 	}
 
-	db, err := postgres.New(config.DSN())
+	log = logging.New(config.LogLevel(), config.LogFormat())
+	slog.SetDefault(log)
+
+	db, err := postgres.New(config.DSN(), log)
 
 	if err != nil {
-		log.Fatal("Error: failed connecting to database: ", err)
+		fatal(log, "failed connecting to database", err)
 	}
-	defer db.Close()
 
 	if err := db.InitDB(ctx); err != nil {
-		log.Fatal("Error: failed initializing storage: ", err)
+		fatal(log, "failed initializing storage", err)
 	}
 
 	// init nats connection
 	nc, err := nats.Connect(fmt.Sprintf("nats://%s", config.NATSAddr()))
 
 	if err != nil {
-		log.Fatal("Error: failed connecting to NATS: ", err)
+		fatal(log, "failed connecting to NATS", err)
 	}
 	defer nc.Flush()
 	defer nc.Close()
 
-	cm, err := subscriber.New(nc)
+	streaming := config.Streaming()
+
+	cm, err := subscriber.New(log, nc, subscriber.Config{
+		ClusterID:   streaming.ClusterID,
+		ClientID:    streaming.ClientID,
+		Channel:     streaming.Channel,
+		DurableName: streaming.DurableName,
+		QueueGroup:  streaming.QueueGroup,
+		MaxInflight: streaming.MaxInflight,
+		AckWait:     streaming.AckWait,
+		MaxLag:      streaming.MaxLag,
+	})
 
 	if err != nil {
-		log.Fatal("Error: failed creating consumer: ", err)
+		fatal(log, "failed creating consumer", err)
 	}
 
 	// subscribe for messages
 	ch, err := cm.Subscribe()
 
 	if err != nil {
-		log.Fatal("Error: subscribe to cluster: ", err)
+		fatal(log, "subscribe to cluster", err)
 	}
 
-	// main service init
-	svc := service.New(ctx, db)
+	// status change events come from their own channel
+	statusSub, err := subscriber.New(log, nc, subscriber.Config{
+		ClusterID:   streaming.ClusterID,
+		ClientID:    streaming.ClientID + "-status",
+		Channel:     streaming.StatusChannel,
+		DurableName: streaming.DurableName,
+		QueueGroup:  streaming.QueueGroup,
+		MaxInflight: streaming.MaxInflight,
+		AckWait:     streaming.AckWait,
+		MaxLag:      streaming.MaxLag,
+	})
+
+	if err != nil {
+		fatal(log, "failed creating status consumer", err)
+	}
+
+	statusCh, err := statusSub.Subscribe()
+
+	if err != nil {
+		fatal(log, "subscribe to status channel", err)
+	}
+
+	pub, err := publisher.New(log, nc, streaming.ClusterID, streaming.ClientID+"-pub")
+
+	if err != nil {
+		fatal(log, "failed creating publisher", err)
+	}
+
+	deadLetters := deadletter.New(log, pub, db, config.DeadLetterChannel())
+
+	// creating cache, it is warmed up once the server is up,
+	// with the service reported not ready until then
+	cacheConfig := config.Cache()
+	localCache, err := cache.NewSharded[string, *domain.Order](cache.Config{
+		Capacity: cacheConfig.Capacity,
+		MaxBytes: cacheConfig.MaxBytes,
+		TTL:      cacheConfig.TTL,
+		Eviction: cache.EvictionPolicy(cacheConfig.Eviction),
+	}, cacheConfig.Shards)
+	if err != nil {
+		fatal(log, "failed creating cache", err)
+	}
+	go localCache.RunExpiry(ctx, cacheConfig.ExpiryInterval)
+
+	var orderCache cache.Cache[string, *domain.Order] = localCache
+	var redisClient *redis.Client
+
+	if cacheConfig.Backend != "memory" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cacheConfig.Redis.Addr,
+			Password: cacheConfig.Redis.Password,
+			DB:       cacheConfig.Redis.DB,
+		})
+
+		sharedCache := cache.NewRedis[string, *domain.Order](log, redisClient, cache.RedisConfig{
+			Prefix:  cacheConfig.Redis.Prefix,
+			TTL:     cacheConfig.TTL,
+			Timeout: cacheConfig.Redis.Timeout,
+		})
+
+		switch cacheConfig.Backend {
+		case "redis":
+			orderCache = sharedCache
+		case "layered":
+			layered := cache.NewLayered[string, *domain.Order](log, localCache, sharedCache,
+				redisClient, cacheConfig.Redis.InvalidationChannel)
+			go layered.RunInvalidation(ctx)
+			orderCache = layered
+		default:
+			fatal(log, "failed creating cache", fmt.Errorf("unknown cache backend %q", cacheConfig.Backend))
+		}
+	}
+
+	// the ingest path populates the cache according to the write policy,
+	// the read path also finds there the orders kept apart by the policy
+	ingestCache, writtenCache, err := cache.NewWriter(orderCache, cache.WritePolicy(cacheConfig.WritePolicy), cacheConfig.Recent)
+	if err != nil {
+		fatal(log, "failed creating cache writer", err)
+	}
 
-	// start business logic
-	go svc.Run(ch)
+	// orders found missing are remembered by the read path until stored
+	lookups := get.NewCoalescing(db, cacheConfig.NotFoundTTL)
+	ingestCache = lookups.Forgetting(ingestCache)
 
-	// creating cache
-	cache := cache.New(200)
-	if err := cache.RestoreFromDB(log, ctx, config.DSN()); err != nil {
-		log.Print("Error: failed restore cache: ", err)
+	warmer, err := warmup.New(log, warmup.Mode(cacheConfig.Warmup.Mode), cacheConfig.Warmup.Orders, db, orderCache)
+	if err != nil {
+		fatal(log, "failed creating cache warmer", err)
 	}
 
+	// lookups finding the order are counted for the frequent warm-up mode
+	readCache := warmup.NewTracker(writtenCache, db, cacheConfig.Warmup.StatsRetention)
+	go readCache.Run(ctx, log, cacheConfig.Warmup.StatsInterval)
+
+	var lookupCache cache.Cache[string, *domain.Order] = readCache
+	var trace *cache.Recording[string, *domain.Order]
+
+	if cacheConfig.TracePath != "" {
+		f, err := os.OpenFile(cacheConfig.TracePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fatal(log, "failed opening cache trace", err)
+		}
+
+		trace = cache.NewRecording[string, *domain.Order](readCache, f)
+		lookupCache = trace
+	}
+
+	// main service init
+	svc := service.New(ctx, log, db, ingestCache, deadLetters, service.Config{
+		MaxAttempts:  config.MaxAttempts(),
+		Workers:      config.Workers(),
+		MaxInflight:  streaming.MaxInflight,
+		PartitionKey: service.PartitionKey(config.PartitionKey()),
+		BatchSize:    config.BatchSize(),
+		BatchTimeout: config.BatchTimeout(),
+	})
+
+	// start business logic, the pipeline is drained on shutdown
+	var pipeline sync.WaitGroup
+	pipeline.Add(2)
+	go func() {
+		defer pipeline.Done()
+		svc.Run(ch)
+	}()
+	go func() {
+		defer pipeline.Done()
+		svc.RunStatusEvents(statusCh)
+	}()
+
+	// dependencies checked by the health probes
+	probes := health.New()
+	probes.AddCheck("postgres", db.Ping)
+	probes.AddCheck("nats_orders", cm.Check)
+	probes.AddCheck("nats_status", statusSub.Check)
+	probes.AddCheck("nats_publisher", pub.Check)
+	if redisClient != nil {
+		probes.AddCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+
+	// idempotency keys of the order creation requests expire
+	idempotency := config.Idempotency()
+	go create.RunKeyPurge(ctx, log, db, idempotency.KeyTTL, idempotency.PurgeInterval)
+
 	// create http router
 	router := mux.NewRouter()
 
-	// logger mw
+	// request id and logger mw
+	router.Use(logger.RequestID)
 	router.Use(logger.New(log))
 
+	// metrics mw
+	router.Use(metrics.Middleware)
+
 	router.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprint(w, "pong")
 	}).Methods("GET")
 
-	router.HandleFunc("/orders/{order_uid:[a-z0-9]{19}}", get.New(log, db, cache)).Methods("GET")
+	router.HandleFunc("/healthz", healthhttp.NewHealthz(log, probes)).Methods("GET")
+	router.HandleFunc("/readyz", healthhttp.NewReadyz(log, probes)).Methods("GET")
+
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	router.HandleFunc("/orders", list.New(log, db)).Methods("GET")
+	router.HandleFunc("/orders", create.New(log, svc, db, db, idempotency.ClaimTimeout)).Methods("POST")
+	router.HandleFunc("/dead-letters", dllist.New(log, deadLetters)).Methods("GET")
+	router.HandleFunc("/dead-letters/{id:[0-9]+}/replay", replay.New(log, deadLetters)).Methods("POST")
+	router.HandleFunc("/orders/{order_uid:[a-z0-9]{19}}", get.New(log, lookups, cache.NewCounting(lookupCache))).Methods("GET")
+	router.HandleFunc("/orders/{order_uid:[a-z0-9]{19}}/history", history.New(log, db)).Methods("GET")
 
 	srv := &http.Server{
 		Addr:         config.HTTPAddr(),
@@ -105,77 +287,171 @@ func main() {
 		IdleTimeout:  time.Second * 30,
 	}
 
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
 	// start event publisher app
-	go publisher(10341, log, nc)
+	go publishOrders(ctx, 10341, log, pub, streaming.Channel)
 
 	stopped := make(chan struct{})
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 		<-sigint
-		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
 
-		// saving cache to DB
-		if err := cache.EvacuateToDB(log, config.DSN()); err != nil {
-			log.Fatal("Error: failed evacuate cache: ", err)
+		// taking the instance out of load balancing first, the server
+		// keeps answering until the load balancers see it not ready
+		probes.SetState(health.StateStopping)
+
+		log.Info("waiting for the load balancers", slog.Duration("delay", config.ShutdownDelay()))
+		time.Sleep(config.ShutdownDelay())
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), config.ShutdownTimeout())
+		defer cancelShutdown()
+
+		log.Info("stopping server")
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error("HTTP server shutdown error", logging.Err(err))
+		}
+
+		// no new messages are taken, those not taken yet are left
+		// unacked for redelivery, the ones being handled are finished
+		log.Info("draining message pipeline")
+		cancel()
+		cm.Stop()
+		statusSub.Stop()
+
+		drained := make(chan struct{})
+		go func() {
+			pipeline.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+			log.Info("message pipeline drained")
+		case <-shutdownCtx.Done():
+			// nothing is closed under the messages still being handled
+			log.Warn("shutdown deadline exceeded, in-flight messages will be redelivered")
+			svc.Abort()
+			<-drained
+		}
+
+		for _, c := range []struct {
+			name   string
+			closer io.Closer
+		}{
+			{"orders subscriber", cm},
+			{"status subscriber", statusSub},
+			{"publisher", pub},
+		} {
+			if err := c.closer.Close(); err != nil {
+				log.Error("failed closing "+c.name, logging.Err(err))
+			}
 		}
-		log.Printf("Cache evacuated successfully, length: [%d]", cache.Len())
 
-		log.Print("Stopping server")
-		if err := srv.Shutdown(ctx); err != nil {
-			log.Printf("HTTP Server Shutdown Error: %v", err)
+		// snapshotting the in-memory cache, the shared one outlives the service
+		if cacheConfig.Backend == "memory" {
+			if count, err := cache.WriteSnapshot(cacheConfig.SnapshotPath, localCache); err != nil {
+				log.Error("failed writing cache snapshot", logging.Err(err))
+			} else {
+				log.Info("cache snapshot written", slog.Int("count", count))
+			}
 		}
+
+		if trace != nil {
+			if err := trace.Close(); err != nil {
+				log.Error("failed closing cache trace", logging.Err(err))
+			}
+		}
+
+		if err := readCache.Flush(shutdownCtx); err != nil {
+			log.Error("failed recording access stats", logging.Err(err))
+		}
+
+		if redisClient != nil {
+			if err := redisClient.Close(); err != nil {
+				log.Error("failed closing redis client", logging.Err(err))
+			}
+		}
+
+		if err := db.Close(); err != nil {
+			log.Error("failed closing database", logging.Err(err))
+		}
+
 		close(stopped)
 	}()
 
-	log.Printf("Starting HTTP server on: %s", config.HTTPAddr())
+	// warming the cache up in the background, reported by the readiness probe
+	go func() {
+		if !probes.SwapState(health.StateStarting, health.StateWarming) {
+			return
+		}
+
+		if cacheConfig.Backend == "memory" && cacheConfig.Warmup.Mode == string(warmup.ModeSnapshot) {
+			count, err := cache.ReadSnapshot(cacheConfig.SnapshotPath, localCache)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				log.Info("no cache snapshot to restore")
+			case err != nil:
+				log.Error("failed restoring cache snapshot", logging.Err(err))
+			default:
+				log.Info("cache restored from snapshot", slog.Int("count", count))
+			}
+		}
+
+		if count, err := warmer.Run(ctx); err != nil {
+			log.Error("failed warming cache up", logging.Err(err), slog.Int("count", count))
+		} else if count > 0 {
+			log.Info("cache warmed up", slog.String("mode", cacheConfig.Warmup.Mode), slog.Int("count", count))
+		}
+
+		if cacheConfig.Backend == "memory" {
+			go cache.RunSnapshots(ctx, log, cacheConfig.SnapshotPath, cacheConfig.SnapshotInterval, localCache)
+		}
+
+		probes.SwapState(health.StateWarming, health.StateReady)
+	}()
+
+	log.Info("starting HTTP server", slog.String("addr", config.HTTPAddr()))
 
 	// start http server
 	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatal("Error: HTTP server ListenAndServe error: ", err)
+		fatal(log, "HTTP server ListenAndServe error", err)
 	}
 
 	<-stopped
 }
 
-func publisher(ordersCount int, log *log.Logger, nc *nats.Conn) {
-	const channel = "order-notification"
-
-	log.Print("Publisher started")
+// publishOrders publishes test orders on the shared publisher connection,
+// losing it is reported by the health probes, and it stops on shutdown
+func publishOrders(ctx context.Context, ordersCount int, log *slog.Logger, pub *publisher.Publisher, channel string) {
+	log.Info("publisher started")
 
 	data, err := os.ReadFile("data/model.json")
 	if err != nil {
-		log.Fatal("Error: failed reading file: ", err)
+		log.Error("failed reading file", logging.Err(err))
+		return
 	}
 
 	var order domain.Order
 	_ = json.Unmarshal(data, &order)
 
-	sc, err := stan.Connect("dev", "order-producer", stan.NatsConn(nc),
-		stan.SetConnectionLostHandler(func(_ stan.Conn, reason error) {
-			log.Fatal("Error: NATS connection lost, reason: ", reason)
-		}))
-
-	if err != nil {
-		log.Fatal("Error: publisher failed connecting to cluster: ", err)
-	}
-
 	fo, err := os.Create("data/uids.txt")
 	if err != nil {
-		panic(err)
+		log.Error("failed creating uids file", logging.Err(err))
+		return
 	}
 
 	defer func() {
 		if err := fo.Close(); err != nil {
-			panic(err)
+			log.Error("failed closing uids file", logging.Err(err))
 		}
 	}()
 
 	for i := 0; i < ordersCount; i++ {
+		if ctx.Err() != nil {
+			log.Info("publisher stopped", slog.Int("count", i))
+			return
+		}
+
 		uid := utils.GenerateUID19v2()
 		fo.Write([]byte(uid + "\n"))
 
@@ -184,29 +460,27 @@ func publisher(ordersCount int, log *log.Logger, nc *nats.Conn) {
 
 		data, err = json.Marshal(order)
 		if err != nil {
-			log.Fatal("Error: failed marshal data: ", err)
+			log.Error("failed marshal data", logging.Err(err))
+			return
 		}
 
-		if err := sc.Publish(channel, data); err != nil {
-			log.Fatal("Error: failed publish message: ", err)
+		if err := pub.Publish(channel, data); err != nil {
+			log.Error("failed publish message", logging.Err(err))
+			return
 		}
 
 		if (i % 100) == 0 {
-			log.Printf("Messages count statistics: %d", i)
+			log.Debug("messages count statistics", slog.Int("count", i))
 		}
 
 		time.Sleep(time.Millisecond * 1)
 	}
 
-	log.Print("Publisher finished")
-
-	errFlush := nc.Flush()
-	if errFlush != nil {
-		panic(errFlush)
-	}
+	log.Info("publisher finished")
+}
 
-	errLast := nc.LastError()
-	if errLast != nil {
-		panic(errLast)
-	}
+// fatal logs the error and exits, like log.Fatal does
+func fatal(log *slog.Logger, msg string, err error) {
+	log.Error(msg, logging.Err(err))
+	os.Exit(1)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"test-task/order-service/internal/config"
+	"test-task/order-service/internal/storage/postgres"
+)
+
+const migrateUsage = "usage: order-service migrate [up | down [steps] | version]"
+
+// migrate runs the "order-service migrate" subcommand
+func migrate(ctx context.Context, log *slog.Logger, args []string) error {
+	const op = "main.migrate"
+
+	config, err := config.New()
+	if err != nil {
+		return fmt.Errorf("%s: initializing config: %w", op, err)
+	}
+
+	db, err := postgres.New(config.DSN(), log)
+	if err != nil {
+		return fmt.Errorf("%s: connecting to database: %w", op, err)
+	}
+	defer db.Close()
+
+	m, err := db.Migrator()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("%s: invalid steps count: %q", op, args[1])
+			}
+		}
+		return m.Down(ctx, steps)
+	case "version":
+		v, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("schema version", slog.Int("version", v))
+		return nil
+	default:
+		return fmt.Errorf("%s: unknown command %q, %s", op, cmd, migrateUsage)
+	}
+}
@@ -0,0 +1,169 @@
+package cache_test
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"test-task/order-service/internal/cache"
+	"testing"
+)
+
+const benchCapacity = 500
+
+type trace struct {
+	name string
+	keys []string
+}
+
+// supportTrace mimics the order lookups: a small set of hot orders
+// looked up by support tooling among lookups of random cold orders,
+// with a periodic scan over a range of cold ones
+func supportTrace(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	hot := rand.NewZipf(r, 1.2, 1, 99)
+
+	keys := make([]string, 0, n)
+	for len(keys) < n {
+		if len(keys)%5000 == 0 {
+			start := r.Intn(1_000_000)
+			for i := 0; i < 2000 && len(keys) < n; i++ {
+				keys = append(keys, fmt.Sprintf("cold%d", start+i))
+			}
+			continue
+		}
+
+		if r.Intn(10) < 8 {
+			keys = append(keys, fmt.Sprintf("hot%d", hot.Uint64()))
+		} else {
+			keys = append(keys, fmt.Sprintf("cold%d", r.Intn(1_000_000)))
+		}
+	}
+
+	return keys
+}
+
+// zipfTrace draws keys from a skewed distribution without scans
+func zipfTrace(n int) []string {
+	r := rand.New(rand.NewSource(2))
+	z := rand.NewZipf(r, 1.1, 1, 9_999)
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("order%d", z.Uint64())
+	}
+
+	return keys
+}
+
+// loopTrace repeatedly scans a range slightly larger than the cache
+func loopTrace(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("order%d", i%(benchCapacity+benchCapacity/10))
+	}
+
+	return keys
+}
+
+// recordedTraces loads the traces in testdata/traces, each holding one
+// looked up order_uid per line. They are recorded by running the service
+// with cache.trace_path set and copying the file there as <name>.trace
+func recordedTraces(b *testing.B) []trace {
+	files, err := filepath.Glob(filepath.Join("testdata", "traces", "*.trace"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var traces []trace
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var keys []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if key := strings.TrimSpace(scanner.Text()); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		f.Close()
+
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+
+		traces = append(traces, trace{name: strings.TrimSuffix(filepath.Base(file), ".trace"), keys: keys})
+	}
+
+	return traces
+}
+
+// Benchmark_EvictionPolicies replays each trace read-through against
+// every policy, reporting the hit ratio along with the time per lookup
+func Benchmark_EvictionPolicies(b *testing.B) {
+	traces := append([]trace{
+		{name: "support", keys: supportTrace(100_000)},
+		{name: "zipf", keys: zipfTrace(100_000)},
+		{name: "loop", keys: loopTrace(100_000)},
+	}, recordedTraces(b)...)
+
+	for _, tr := range traces {
+		for _, policy := range policies {
+			tr, policy := tr, policy
+
+			b.Run(tr.name+"/"+string(policy), func(b *testing.B) {
+				c, err := cache.NewWithConfig[string, int](cache.Config{Capacity: benchCapacity, Eviction: policy})
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				hits := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					key := tr.keys[i%len(tr.keys)]
+					if _, ok := c.Get(key); ok {
+						hits++
+					} else {
+						c.Add(key, i)
+					}
+				}
+
+				b.ReportMetric(float64(hits)/float64(b.N), "hit-ratio")
+			})
+		}
+	}
+}
+
+// Benchmark_Contention compares a single segment with a sharded cache
+// under concurrent lookups of the support trace
+func Benchmark_Contention(b *testing.B) {
+	keys := supportTrace(100_000)
+
+	for _, shards := range []int{1, 16, 64} {
+		shards := shards
+
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			c, err := cache.NewSharded[string, int](cache.Config{Capacity: benchCapacity * 4}, shards)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := rand.Intn(len(keys))
+				for pb.Next() {
+					key := keys[i%len(keys)]
+					if _, ok := c.Get(key); !ok {
+						c.Add(key, i)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
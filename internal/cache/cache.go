@@ -1,231 +1,310 @@
 package cache
 
 import (
-	"container/list"
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"sync"
-	"test-task/order-service/internal/domain"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/jmoiron/sqlx"
+	"test-task/order-service/internal/metrics"
+	"time"
 )
 
-type Cache interface {
-	Add(key string, value interface{}) bool
-	Get(key string) interface{}
+// Cache maps keys of type K to values of type V
+type Cache[K comparable, V any] interface {
+	Add(key K, value V) bool
+	Get(key K) (V, bool)
+	Remove(key K) bool
+	Len() int
 }
 
-type Item struct {
-	Key   string
-	Value interface{}
+// Sizer is implemented by values that can estimate their own memory
+// footprint, only those count against the bytes budget
+type Sizer interface {
+	SizeEstimate() int64
 }
 
-type LRUCache struct {
-	capacity int
-	queue    *list.List
-	mutex    *sync.RWMutex
-	items    map[string]*list.Element
+type Item[K comparable, V any] struct {
+	Key       K
+	Value     V
+	Size      int64
+	ExpiresAt time.Time
 }
 
-func New(cap int) *LRUCache {
-	return &LRUCache{
-		capacity: cap,
-		queue:    list.New(),
-		mutex:    new(sync.RWMutex),
-		items:    make(map[string]*list.Element),
-	}
+func (i *Item[K, V]) expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && now.After(i.ExpiresAt)
 }
 
-func (c *LRUCache) Add(key string, value interface{}) bool {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// Config bounds the cache. Entries are evicted as decided by Eviction
+// once there are more than Capacity of them or their estimated sizes
+// exceed MaxBytes, and expire TTL after they were added. Zero MaxBytes
+// or TTL disables the bound, an empty Eviction means LRU
+type Config struct {
+	Capacity int
+	MaxBytes int64
+	TTL      time.Duration
+	Eviction EvictionPolicy
+}
 
-	if item, exists := c.items[key]; exists {
-		c.queue.MoveToFront(item)
-		item.Value.(*Item).Value = value
-		return true
-	}
+// Stats is a snapshot of the cache occupancy and counters
+type Stats struct {
+	Len         int   `json:"len"`
+	Bytes       int64 `json:"bytes"`
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Evictions   int64 `json:"evictions"`
+	Expirations int64 `json:"expirations"`
+}
 
-	if c.queue.Len() == c.capacity {
-		c.clear()
-	}
+// LocalCache is an in-memory cache bounded by its Config, which entries
+// it evicts is decided by the eviction policy
+type LocalCache[K comparable, V any] struct {
+	capacity int
+	maxBytes int64
+	ttl      time.Duration
+	bytes    int64
+	stats    Stats
+	now      func() time.Time
+	policy   evictor[K]
+	mutex    *sync.RWMutex
+	items    map[K]*Item[K, V]
+}
 
-	item := &Item{
-		Key:   key,
-		Value: value,
-	}
+// New creates an LRU cache holding up to cap entries
+func New[K comparable, V any](cap int) *LocalCache[K, V] {
+	c, _ := NewWithConfig[K, V](Config{Capacity: cap, Eviction: LRU})
+	return c
+}
 
-	element := c.queue.PushFront(item)
-	c.items[item.Key] = element
+func NewWithConfig[K comparable, V any](cfg Config) (*LocalCache[K, V], error) {
+	const op = "cache.NewWithConfig"
 
-	return true
+	policy, err := newEvictor[K](cfg.Eviction, cfg.Capacity)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &LocalCache[K, V]{
+		capacity: cfg.Capacity,
+		maxBytes: cfg.MaxBytes,
+		ttl:      cfg.TTL,
+		now:      time.Now,
+		policy:   policy,
+		mutex:    new(sync.RWMutex),
+		items:    make(map[K]*Item[K, V]),
+	}, nil
 }
 
-func (c *LRUCache) Get(key string) interface{} {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+func (c *LocalCache[K, V]) Add(key K, value V) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	element, exists := c.items[key]
-	if !exists {
-		return nil
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
 	}
 
-	c.queue.MoveToFront(element)
-	return element.Value.(*Item).Value
+	return c.add(key, value, expiresAt)
 }
 
-func (c *LRUCache) Remove(key string) bool {
+// Restore adds an entry saved from a cache, keeping its expiry.
+// Entries that have expired meanwhile are not added
+func (c *LocalCache[K, V]) Restore(item Item[K, V]) bool {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if value, found := c.items[key]; found {
-		c.deleteItem(value)
+	if item.expired(c.now()) {
+		return false
 	}
 
-	return true
+	return c.add(item.Key, item.Value, item.ExpiresAt)
 }
 
-func (c *LRUCache) Len() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return len(c.items)
-}
+func (c *LocalCache[K, V]) add(key K, value V, expiresAt time.Time) bool {
+	var size int64
+	if sizer, ok := any(value).(Sizer); ok {
+		size = sizer.SizeEstimate()
+	}
 
-func (c *LRUCache) clear() {
-	if element := c.queue.Back(); element != nil {
-		c.deleteItem(element)
+	item, exists := c.items[key]
+
+	// an entry that alone exceeds the budget would flush the whole cache
+	if c.maxBytes > 0 && size > c.maxBytes {
+		if exists {
+			c.deleteItem(item)
+		}
+		return false
 	}
-}
 
-func (c *LRUCache) deleteItem(elem *list.Element) {
-	item := c.queue.Remove(elem).(*Item)
-	delete(c.items, item.Key)
-}
+	if exists {
+		c.policy.access(key)
+		c.bytes += size - item.Size
+		item.Value, item.Size, item.ExpiresAt = value, size, expiresAt
 
-func (c *LRUCache) EvacuateToDB(log *log.Logger, dbUri string) error {
-	const op = "cache.EvacuateToDB"
+		for c.maxBytes > 0 && c.bytes > c.maxBytes {
+			if !c.evict() {
+				break
+			}
+		}
 
-	if c.Len() == 0 {
-		log.Print("Cache is already clear")
-		return nil
+		_, exists = c.items[key]
+		return exists
 	}
 
-	const createCacheTable = `
-		CREATE TABLE IF NOT EXISTS cache (
-			id CHAR(19) PRIMARY KEY,
-			data JSONB NOT NULL,
-			UNIQUE (id, data)
-		);
-	`
+	// making room before the entry is added, so the policy
+	// doesn't pick the entry it has just seen as the victim
+	for len(c.items) >= c.capacity || (c.maxBytes > 0 && c.bytes+size > c.maxBytes) {
+		if !c.evict() {
+			break
+		}
+	}
 
-	db, err := sqlx.Open("pgx", dbUri)
-	if err != nil {
-		return fmt.Errorf("%s: open db connection: %w", op, err)
+	if c.capacity <= 0 {
+		return false
 	}
 
-	_, err = db.Exec(createCacheTable)
-	if err != nil {
-		return fmt.Errorf("%s: creating table: %w", op, err)
+	c.items[key] = &Item[K, V]{
+		Key:       key,
+		Value:     value,
+		Size:      size,
+		ExpiresAt: expiresAt,
 	}
+	c.bytes += size
+	c.policy.add(key)
 
-	q := `INSERT INTO cache (id, data) VALUES ($1, $2)`
+	return true
+}
 
-	stmt, err := db.Prepare(q)
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+func (c *LocalCache[K, V]) Get(key K) (V, bool) {
+	// the policy records the access, a read lock is not enough
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	item, exists := c.items[key]
+	if exists && item.expired(c.now()) {
+		c.expire(item)
+		exists = false
+	}
+
+	if !exists {
+		c.stats.Misses++
+		var zero V
+		return zero, false
 	}
 
-	count := 0
-	for elem := c.queue.Front(); elem != nil; elem = elem.Next() {
-		order := elem.Value.(*Item).Value.(*domain.Order)
+	c.stats.Hits++
 
-		if _, err := stmt.Exec(order.OrderUid, order); err != nil {
-			log.Print(fmt.Errorf("%s: saving entry: %w", op, err))
-		}
+	c.policy.access(key)
+	return item.Value, true
+}
+
+func (c *LocalCache[K, V]) Remove(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-		count++
-		log.Printf("Cache entry with id: [%s] has been saved", order.OrderUid)
+	if item, found := c.items[key]; found {
+		c.deleteItem(item)
 	}
-	log.Printf("cache loop ended, queue len: [%d]", count)
 
-	return nil
+	return true
 }
 
-func (c *LRUCache) RestoreFromDB(log *log.Logger, ctx context.Context, dbUri string) error {
-	const op = "cache.RestoreFromDB"
+func (c *LocalCache[K, V]) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.items)
+}
 
-	const qCheckIfExists = `SELECT 
-		    COUNT(table_name)
-		FROM 
-		    information_schema.tables 
-		WHERE 
-		    table_schema LIKE 'public' AND 
-		    table_type LIKE 'BASE TABLE' AND
-			table_name = 'cache';
-	`
+// Stats returns the current occupancy along with the counters
+// accumulated since the cache was created
+func (c *LocalCache[K, V]) Stats() Stats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 
-	db, err := sqlx.Open("pgx", dbUri)
-	if err != nil {
-		return fmt.Errorf("%s: open db connection: %w", op, err)
-	}
+	stats := c.stats
+	stats.Len = len(c.items)
+	stats.Bytes = c.bytes
+	return stats
+}
 
-	stmt, err := db.Prepare(qCheckIfExists)
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
-	}
+// RemoveExpired drops all entries past their TTL and returns their count
+func (c *LocalCache[K, V]) RemoveExpired() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	var count int
+	now := c.now()
+	removed := 0
 
-	err = stmt.QueryRowContext(ctx).Scan(&count)
-	if err != nil {
-		return fmt.Errorf("%s: checking if cache table exists: %w", op, err)
+	for _, item := range c.items {
+		if item.expired(now) {
+			c.expire(item)
+			removed++
+		}
 	}
 
-	if count == 0 {
-		log.Printf("Cache is empty")
-		return nil
-	}
+	return removed
+}
 
-	stmt, err = db.PrepareContext(ctx, "SELECT id, data FROM cache")
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+// RunExpiry removes expired entries every interval until ctx is done,
+// so entries that are never read again do not hold memory until evicted
+func (c *LocalCache[K, V]) RunExpiry(ctx context.Context, interval time.Duration) {
+	if c.ttl <= 0 || interval <= 0 {
+		return
 	}
-	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx)
-	if err != nil {
-		return fmt.Errorf("%s: querying stmt: %w", op, err)
-	}
-	defer rows.Close()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	for rows.Next() {
-		var id string
-		var data []byte
-		if err := rows.Scan(&id, &data); err != nil {
-			return fmt.Errorf("%s: scanning cache rows: %w", op, err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RemoveExpired()
 		}
+	}
+}
 
-		var order domain.Order
-		err = json.Unmarshal(data, &order)
+// Range calls fn for the entries that have not expired, in no particular
+// order, until fn returns false. The cache must not be modified from fn
+func (c *LocalCache[K, V]) Range(fn func(item Item[K, V]) bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 
-		if err != nil {
-			return fmt.Errorf("%s: unmarshalling data: %w", op, err)
+	now := c.now()
+	for _, item := range c.items {
+		if item.expired(now) {
+			continue
+		}
+		if !fn(*item) {
+			return
 		}
-
-		c.Add(id, &order)
 	}
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("%s: scanning rows: %w", op, err)
+}
+
+// evict removes the entry picked by the policy, reporting whether there was one
+func (c *LocalCache[K, V]) evict() bool {
+	key, ok := c.policy.evict()
+	if !ok {
+		return false
 	}
 
-	_, err = db.ExecContext(ctx, "TRUNCATE TABLE cache")
-	if err != nil {
-		return fmt.Errorf("%s: truncating cache table: %w", op, err)
+	if item, exists := c.items[key]; exists {
+		c.bytes -= item.Size
+		delete(c.items, key)
+		c.stats.Evictions++
+		metrics.CacheEvictions.Inc()
 	}
 
-	log.Printf("Cache fully restored")
-	return nil
+	return true
+}
+
+func (c *LocalCache[K, V]) expire(item *Item[K, V]) {
+	c.deleteItem(item)
+	c.stats.Expirations++
+	metrics.CacheExpirations.Inc()
+}
+
+func (c *LocalCache[K, V]) deleteItem(item *Item[K, V]) {
+	c.policy.remove(item.Key)
+	c.bytes -= item.Size
+	delete(c.items, item.Key)
 }
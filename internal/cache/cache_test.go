@@ -0,0 +1,136 @@
+package cache_test
+
+import (
+	"context"
+	"test-task/order-service/internal/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sized is a value with a fixed size estimate
+type sized int64
+
+func (s sized) SizeEstimate() int64 { return int64(s) }
+
+func Test_LRUCacheEviction(t *testing.T) {
+	test_cases := []struct {
+		test_name     string
+		cfg           cache.Config
+		sizes         []int64
+		wantKeys      []string
+		wantBytes     int64
+		wantEvictions int64
+	}{
+		{
+			test_name:     "By capacity",
+			cfg:           cache.Config{Capacity: 2},
+			sizes:         []int64{10, 10, 10},
+			wantKeys:      []string{"k1", "k2"},
+			wantBytes:     20,
+			wantEvictions: 1,
+		},
+		{
+			test_name:     "By bytes",
+			cfg:           cache.Config{Capacity: 10, MaxBytes: 25},
+			sizes:         []int64{10, 10, 10},
+			wantKeys:      []string{"k1", "k2"},
+			wantBytes:     20,
+			wantEvictions: 1,
+		},
+		{
+			test_name:     "Large entry evicts several",
+			cfg:           cache.Config{Capacity: 10, MaxBytes: 30},
+			sizes:         []int64{10, 10, 25},
+			wantKeys:      []string{"k2"},
+			wantBytes:     25,
+			wantEvictions: 2,
+		},
+		{
+			test_name: "Entry over the budget is not cached",
+			cfg:       cache.Config{Capacity: 10, MaxBytes: 30},
+			sizes:     []int64{10, 40},
+			wantKeys:  []string{"k0"},
+			wantBytes: 10,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := cache.NewWithConfig[string, sized](tc.cfg)
+			assert.NoError(t, err)
+
+			keys := []string{"k0", "k1", "k2"}
+			for i, size := range tc.sizes {
+				c.Add(keys[i], sized(size))
+			}
+
+			stats := c.Stats()
+			assert.Equal(t, len(tc.wantKeys), stats.Len)
+			assert.Equal(t, tc.wantBytes, stats.Bytes)
+			assert.Equal(t, tc.wantEvictions, stats.Evictions)
+			for _, key := range tc.wantKeys {
+				_, ok := c.Get(key)
+				assert.True(t, ok)
+			}
+		})
+	}
+}
+
+func Test_LRUCacheReplaceAccountsBytes(t *testing.T) {
+	c, err := cache.NewWithConfig[string, sized](cache.Config{Capacity: 10, MaxBytes: 100})
+	assert.NoError(t, err)
+
+	c.Add("k", sized(30))
+	c.Add("k", sized(50))
+
+	assert.Equal(t, int64(50), c.Stats().Bytes)
+
+	c.Remove("k")
+
+	assert.Equal(t, cache.Stats{}, c.Stats())
+}
+
+func Test_LRUCacheTTL(t *testing.T) {
+	c, err := cache.NewWithConfig[string, string](cache.Config{Capacity: 10, TTL: 20 * time.Millisecond})
+	assert.NoError(t, err)
+
+	c.Add("k", "v")
+	value, ok := c.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "v", value)
+
+	time.Sleep(40 * time.Millisecond)
+
+	_, ok = c.Get("k")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 0, stats.Len)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Expirations)
+}
+
+func Test_LRUCacheRunExpiry(t *testing.T) {
+	c, err := cache.NewWithConfig[string, string](cache.Config{Capacity: 10, TTL: 20 * time.Millisecond})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go c.RunExpiry(ctx, 10*time.Millisecond)
+
+	c.Add("k0", "v")
+	c.Add("k1", "v")
+
+	assert.Eventually(t, func() bool {
+		return c.Stats().Expirations == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, c.Len())
+}
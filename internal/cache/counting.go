@@ -0,0 +1,25 @@
+package cache
+
+import "test-task/order-service/internal/metrics"
+
+// counting counts the hits and misses of the wrapped cache
+type counting[K comparable, V any] struct {
+	Cache[K, V]
+}
+
+// NewCounting wraps c so its lookups are counted in the cache hit and
+// miss metrics. Whatever the backend, the cache the read path uses is
+// wrapped once, so a lookup counts once however many tiers it goes through
+func NewCounting[K comparable, V any](c Cache[K, V]) Cache[K, V] {
+	return counting[K, V]{c}
+}
+
+func (c counting[K, V]) Get(key K) (V, bool) {
+	value, ok := c.Cache.Get(key)
+	if ok {
+		metrics.CacheHits.Inc()
+	} else {
+		metrics.CacheMisses.Inc()
+	}
+	return value, ok
+}
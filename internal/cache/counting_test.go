@@ -0,0 +1,41 @@
+package cache_test
+
+import (
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	var m dto.Metric
+	assert.NoError(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func Test_CountingLayered(t *testing.T) {
+	_, client := newRedis(t)
+
+	log := logging.Discard()
+	shared := cache.NewRedis[string, string](log, client, cache.RedisConfig{Prefix: "order:"})
+	c := cache.NewCounting[string, string](cache.NewLayered[string, string](log, cache.New[string, string](10), shared, client, "invalidation"))
+
+	shared.Add("k", "v")
+
+	hits, misses := counterValue(t, metrics.CacheHits), counterValue(t, metrics.CacheMisses)
+
+	// missing L1 and found in L2 is a single hit
+	_, ok := c.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, hits+1, counterValue(t, metrics.CacheHits))
+	assert.Equal(t, misses, counterValue(t, metrics.CacheMisses))
+
+	_, ok = c.Get("missing")
+	assert.False(t, ok)
+	assert.Equal(t, hits+1, counterValue(t, metrics.CacheHits))
+	assert.Equal(t, misses+1, counterValue(t, metrics.CacheMisses))
+}
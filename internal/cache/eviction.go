@@ -0,0 +1,337 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// EvictionPolicy decides which entry is evicted once the cache is full
+type EvictionPolicy string
+
+const (
+	// LRU evicts the least recently used entry
+	LRU EvictionPolicy = "lru"
+	// LFU evicts the least frequently used entry,
+	// the least recently used one among equally used
+	LFU EvictionPolicy = "lfu"
+	// TwoQueue admits new entries to a FIFO queue and promotes them to
+	// an LRU one only when they are seen again after leaving it, so a
+	// scan of entries used once doesn't flush the hot ones
+	TwoQueue EvictionPolicy = "2q"
+	// ARC balances recency and frequency, adapting the share
+	// of each to the hits on recently evicted entries
+	ARC EvictionPolicy = "arc"
+)
+
+// evictor tracks the keys held by the cache and picks those to evict.
+// It is guarded by the cache mutex
+type evictor[K comparable] interface {
+	// add records a key added to the cache
+	add(key K)
+	// access records a hit on a key held by the cache
+	access(key K)
+	// remove forgets a key removed from the cache
+	remove(key K)
+	// evict picks a key to evict and forgets it
+	evict() (K, bool)
+}
+
+func newEvictor[K comparable](policy EvictionPolicy, capacity int) (evictor[K], error) {
+	switch policy {
+	case LRU, "":
+		return newLRU[K](), nil
+	case LFU:
+		return newLFU[K](), nil
+	case TwoQueue:
+		return newTwoQueue[K](capacity), nil
+	case ARC:
+		return newARC[K](capacity), nil
+	default:
+		return nil, fmt.Errorf("unknown eviction policy %q", policy)
+	}
+}
+
+// keyList is a list of keys with an index, the most recent at the front
+type keyList[K comparable] struct {
+	keys  *list.List
+	index map[K]*list.Element
+}
+
+func newKeyList[K comparable]() *keyList[K] {
+	return &keyList[K]{
+		keys:  list.New(),
+		index: make(map[K]*list.Element),
+	}
+}
+
+func (l *keyList[K]) len() int {
+	return l.keys.Len()
+}
+
+func (l *keyList[K]) contains(key K) bool {
+	_, ok := l.index[key]
+	return ok
+}
+
+func (l *keyList[K]) pushFront(key K) {
+	l.index[key] = l.keys.PushFront(key)
+}
+
+func (l *keyList[K]) moveToFront(key K) bool {
+	elem, ok := l.index[key]
+	if ok {
+		l.keys.MoveToFront(elem)
+	}
+	return ok
+}
+
+func (l *keyList[K]) remove(key K) bool {
+	elem, ok := l.index[key]
+	if ok {
+		l.keys.Remove(elem)
+		delete(l.index, key)
+	}
+	return ok
+}
+
+func (l *keyList[K]) popBack() (K, bool) {
+	elem := l.keys.Back()
+	if elem == nil {
+		var zero K
+		return zero, false
+	}
+
+	key := l.keys.Remove(elem).(K)
+	delete(l.index, key)
+	return key, true
+}
+
+type lru[K comparable] struct {
+	*keyList[K]
+}
+
+func newLRU[K comparable]() lru[K] {
+	return lru[K]{newKeyList[K]()}
+}
+
+func (p lru[K]) add(key K) {
+	p.pushFront(key)
+}
+
+func (p lru[K]) access(key K) {
+	p.moveToFront(key)
+}
+
+func (p lru[K]) remove(key K) {
+	p.keyList.remove(key)
+}
+
+func (p lru[K]) evict() (K, bool) {
+	return p.popBack()
+}
+
+// lfu keeps the keys in buckets by use count, ordered by count,
+// so picking and promoting keys takes constant time
+type lfu[K comparable] struct {
+	buckets *list.List
+	entries map[K]*lfuEntry
+}
+
+type lfuBucket struct {
+	count int
+	keys  *list.List
+}
+
+type lfuEntry struct {
+	bucket *list.Element
+	elem   *list.Element
+}
+
+func newLFU[K comparable]() *lfu[K] {
+	return &lfu[K]{
+		buckets: list.New(),
+		entries: make(map[K]*lfuEntry),
+	}
+}
+
+func (p *lfu[K]) add(key K) {
+	front := p.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).count != 1 {
+		front = p.buckets.PushFront(&lfuBucket{count: 1, keys: list.New()})
+	}
+
+	p.entries[key] = &lfuEntry{
+		bucket: front,
+		elem:   front.Value.(*lfuBucket).keys.PushFront(key),
+	}
+}
+
+func (p *lfu[K]) access(key K) {
+	entry, ok := p.entries[key]
+	if !ok {
+		return
+	}
+
+	current := entry.bucket.Value.(*lfuBucket)
+	next := entry.bucket.Next()
+	if next == nil || next.Value.(*lfuBucket).count != current.count+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{count: current.count + 1, keys: list.New()}, entry.bucket)
+	}
+
+	p.unlink(entry)
+	entry.bucket = next
+	entry.elem = next.Value.(*lfuBucket).keys.PushFront(key)
+}
+
+func (p *lfu[K]) remove(key K) {
+	if entry, ok := p.entries[key]; ok {
+		p.unlink(entry)
+		delete(p.entries, key)
+	}
+}
+
+func (p *lfu[K]) evict() (K, bool) {
+	front := p.buckets.Front()
+	if front == nil {
+		var zero K
+		return zero, false
+	}
+
+	key := front.Value.(*lfuBucket).keys.Back().Value.(K)
+	p.remove(key)
+	return key, true
+}
+
+// unlink removes the entry from its bucket, dropping the bucket once empty
+func (p *lfu[K]) unlink(entry *lfuEntry) {
+	bucket := entry.bucket.Value.(*lfuBucket)
+	bucket.keys.Remove(entry.elem)
+	if bucket.keys.Len() == 0 {
+		p.buckets.Remove(entry.bucket)
+	}
+}
+
+// twoQueue is the full 2Q: new keys go to the in queue, keys evicted from
+// it are remembered in the out queue, and keys added again while still
+// remembered go to the main LRU queue
+type twoQueue[K comparable] struct {
+	in, out, main *keyList[K]
+	inLimit       int
+	outLimit      int
+}
+
+func newTwoQueue[K comparable](capacity int) *twoQueue[K] {
+	// the sizes recommended by the 2Q paper
+	return &twoQueue[K]{
+		in:       newKeyList[K](),
+		out:      newKeyList[K](),
+		main:     newKeyList[K](),
+		inLimit:  max(1, capacity/4),
+		outLimit: max(1, capacity/2),
+	}
+}
+
+func (p *twoQueue[K]) add(key K) {
+	if p.out.remove(key) {
+		p.main.pushFront(key)
+		return
+	}
+	p.in.pushFront(key)
+}
+
+func (p *twoQueue[K]) access(key K) {
+	// hits in the in queue are usually correlated, they don't promote
+	p.main.moveToFront(key)
+}
+
+func (p *twoQueue[K]) remove(key K) {
+	if !p.in.remove(key) {
+		p.main.remove(key)
+	}
+}
+
+func (p *twoQueue[K]) evict() (K, bool) {
+	if p.in.len() > p.inLimit || p.main.len() == 0 {
+		key, ok := p.in.popBack()
+		if ok {
+			p.out.pushFront(key)
+			if p.out.len() > p.outLimit {
+				p.out.popBack()
+			}
+		}
+		return key, ok
+	}
+	return p.main.popBack()
+}
+
+// arc is the Adaptive Replacement Cache: recent holds keys seen once,
+// frequent keys seen at least twice, and their ghost lists remember keys
+// evicted from them. A hit on a ghost grows the target size of its list
+type arc[K comparable] struct {
+	recent, frequent           *keyList[K]
+	recentGhost, frequentGhost *keyList[K]
+	capacity                   int
+	target                     int
+}
+
+func newARC[K comparable](capacity int) *arc[K] {
+	return &arc[K]{
+		recent:        newKeyList[K](),
+		frequent:      newKeyList[K](),
+		recentGhost:   newKeyList[K](),
+		frequentGhost: newKeyList[K](),
+		capacity:      capacity,
+	}
+}
+
+func (p *arc[K]) add(key K) {
+	switch {
+	case p.recentGhost.contains(key):
+		p.target = min(p.capacity, p.target+max(1, p.frequentGhost.len()/p.recentGhost.len()))
+		p.recentGhost.remove(key)
+		p.frequent.pushFront(key)
+	case p.frequentGhost.contains(key):
+		p.target = max(0, p.target-max(1, p.recentGhost.len()/p.frequentGhost.len()))
+		p.frequentGhost.remove(key)
+		p.frequent.pushFront(key)
+	default:
+		p.recent.pushFront(key)
+	}
+
+	// ghosts are bounded to the capacity, as the cache itself is
+	for p.recent.len()+p.recentGhost.len() > p.capacity && p.recentGhost.len() > 0 {
+		p.recentGhost.popBack()
+	}
+	for p.recent.len()+p.frequent.len()+p.recentGhost.len()+p.frequentGhost.len() > 2*p.capacity &&
+		p.frequentGhost.len() > 0 {
+		p.frequentGhost.popBack()
+	}
+}
+
+func (p *arc[K]) access(key K) {
+	if p.recent.remove(key) {
+		p.frequent.pushFront(key)
+		return
+	}
+	p.frequent.moveToFront(key)
+}
+
+func (p *arc[K]) remove(key K) {
+	if !p.recent.remove(key) {
+		p.frequent.remove(key)
+	}
+}
+
+func (p *arc[K]) evict() (K, bool) {
+	if p.recent.len() > 0 && (p.recent.len() > p.target || p.frequent.len() == 0) {
+		key, _ := p.recent.popBack()
+		p.recentGhost.pushFront(key)
+		return key, true
+	}
+
+	key, ok := p.frequent.popBack()
+	if ok {
+		p.frequentGhost.pushFront(key)
+	}
+	return key, ok
+}
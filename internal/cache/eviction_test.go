@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"fmt"
+	"test-task/order-service/internal/cache"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var policies = []cache.EvictionPolicy{cache.LRU, cache.LFU, cache.TwoQueue, cache.ARC}
+
+func Test_EvictionPolicyBounds(t *testing.T) {
+	for _, policy := range policies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			c, err := cache.NewWithConfig[string, int](cache.Config{Capacity: 8, Eviction: policy})
+			assert.NoError(t, err)
+
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("k%d", i%20)
+				c.Add(key, i)
+				c.Get(fmt.Sprintf("k%d", i%7))
+				if i%11 == 0 {
+					c.Remove(key)
+				}
+				assert.LessOrEqual(t, c.Len(), 8)
+			}
+
+			c.Add("last", 1)
+			value, ok := c.Get("last")
+			assert.True(t, ok)
+			assert.Equal(t, 1, value)
+
+			stats := c.Stats()
+			assert.Equal(t, c.Len(), stats.Len)
+			assert.Greater(t, stats.Evictions, int64(0))
+		})
+	}
+}
+
+func Test_EvictionPolicyScan(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		policy    cache.EvictionPolicy
+		wantHot   bool
+	}{
+		{
+			test_name: "LRU is flushed",
+			policy:    cache.LRU,
+		},
+		{
+			test_name: "LFU keeps hot entries",
+			policy:    cache.LFU,
+			wantHot:   true,
+		},
+		{
+			test_name: "2Q keeps hot entries",
+			policy:    cache.TwoQueue,
+			wantHot:   true,
+		},
+		{
+			test_name: "ARC keeps hot entries",
+			policy:    cache.ARC,
+			wantHot:   true,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := cache.NewWithConfig[string, int](cache.Config{Capacity: 16, Eviction: tc.policy})
+			assert.NoError(t, err)
+
+			hot := []string{"hot0", "hot1", "hot2", "hot3"}
+
+			// hot entries are read through: a miss is followed by an add
+			get := func(key string) {
+				if _, ok := c.Get(key); !ok {
+					c.Add(key, 0)
+				}
+			}
+
+			// hot entries looked up among the regular traffic
+			for i := 0; i < 200; i++ {
+				get(hot[i%len(hot)])
+				get(fmt.Sprintf("warm%d", i))
+			}
+
+			// followed by a scan of cold entries
+			for i := 0; i < 100; i++ {
+				get(fmt.Sprintf("cold%d", i))
+			}
+
+			for _, key := range hot {
+				_, ok := c.Get(key)
+				assert.Equal(t, tc.wantHot, ok, key)
+			}
+		})
+	}
+}
+
+func Test_LFUEvictsLeastRecentAmongEqual(t *testing.T) {
+	c, err := cache.NewWithConfig[string, int](cache.Config{Capacity: 3, Eviction: cache.LFU})
+	assert.NoError(t, err)
+
+	c.Add("a", 0)
+	c.Add("b", 0)
+	c.Add("c", 0)
+	c.Get("a")
+	c.Get("c")
+
+	// b is used least, then a and c are used once each but a earlier
+	c.Add("d", 0)
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+
+	c.Add("e", 0)
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+
+	for _, key := range []string{"a", "c", "e"} {
+		_, ok := c.Get(key)
+		assert.True(t, ok, key)
+	}
+}
+
+func Test_UnknownEvictionPolicy(t *testing.T) {
+	_, err := cache.NewWithConfig[string, int](cache.Config{Capacity: 3, Eviction: "mru"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Layered puts an in-process L1 cache in front of the shared L2 one.
+// Lookups missing L1 are filled from L2, adds go to both. Removals are
+// published on the invalidation channel, so that every replica drops
+// the entry from its L1, cached orders only change by being removed.
+// A lookup racing with a removal may refill L1 with the removed entry,
+// which then lives until the L1 TTL
+type Layered[K ~string, V any] struct {
+	log     *slog.Logger
+	l1      Cache[K, V]
+	l2      Cache[K, V]
+	client  redis.UniversalClient
+	channel string
+}
+
+func NewLayered[K ~string, V any](log *slog.Logger, l1, l2 Cache[K, V], client redis.UniversalClient, channel string) *Layered[K, V] {
+	return &Layered[K, V]{
+		log:     log,
+		l1:      l1,
+		l2:      l2,
+		client:  client,
+		channel: channel,
+	}
+}
+
+func (c *Layered[K, V]) Add(key K, value V) bool {
+	c.l1.Add(key, value)
+	return c.l2.Add(key, value)
+}
+
+func (c *Layered[K, V]) Get(key K) (V, bool) {
+	if value, ok := c.l1.Get(key); ok {
+		return value, true
+	}
+
+	value, ok := c.l2.Get(key)
+	if ok {
+		c.l1.Add(key, value)
+	}
+
+	return value, ok
+}
+
+func (c *Layered[K, V]) Remove(key K) bool {
+	const op = "cache.Layered.Remove"
+
+	c.l1.Remove(key)
+	removed := c.l2.Remove(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRedisTimeout)
+	defer cancel()
+
+	if err := c.client.Publish(ctx, c.channel, string(key)).Err(); err != nil {
+		c.log.Warn("failed publishing cache invalidation", slog.String("op", op),
+			slog.String("key", string(key)), slog.Any("error", err))
+	}
+
+	return removed
+}
+
+// Len reports the entries held by L1
+func (c *Layered[K, V]) Len() int {
+	return c.l1.Len()
+}
+
+// RunInvalidation removes the keys published on the invalidation
+// channel from L1 until ctx is done, including the ones published by
+// this replica, which are already removed
+func (c *Layered[K, V]) RunInvalidation(ctx context.Context) {
+	const op = "cache.Layered.RunInvalidation"
+
+	sub := c.client.Subscribe(ctx, c.channel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			c.l1.Remove(K(msg.Payload))
+			c.log.Debug("cache entry invalidated", slog.String("op", op), slog.String("key", msg.Payload))
+		}
+	}
+}
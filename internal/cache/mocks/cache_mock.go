@@ -1,5 +1,10 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: internal/cache/cache.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/cache/cache.go -destination=internal/cache/mocks/cache_mock.go
+//
 
 // Package mock_cache is a generated GoMock package.
 package mock_cache
@@ -7,34 +12,34 @@ package mock_cache
 import (
 	reflect "reflect"
 
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 )
 
 // MockCache is a mock of Cache interface.
-type MockCache struct {
+type MockCache[K comparable, V any] struct {
 	ctrl     *gomock.Controller
-	recorder *MockCacheMockRecorder
+	recorder *MockCacheMockRecorder[K, V]
 }
 
 // MockCacheMockRecorder is the mock recorder for MockCache.
-type MockCacheMockRecorder struct {
-	mock *MockCache
+type MockCacheMockRecorder[K comparable, V any] struct {
+	mock *MockCache[K, V]
 }
 
 // NewMockCache creates a new mock instance.
-func NewMockCache(ctrl *gomock.Controller) *MockCache {
-	mock := &MockCache{ctrl: ctrl}
-	mock.recorder = &MockCacheMockRecorder{mock}
+func NewMockCache[K comparable, V any](ctrl *gomock.Controller) *MockCache[K, V] {
+	mock := &MockCache[K, V]{ctrl: ctrl}
+	mock.recorder = &MockCacheMockRecorder[K, V]{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockCache) EXPECT() *MockCacheMockRecorder {
+func (m *MockCache[K, V]) EXPECT() *MockCacheMockRecorder[K, V] {
 	return m.recorder
 }
 
 // Add mocks base method.
-func (m *MockCache) Add(key string, value interface{}) bool {
+func (m *MockCache[K, V]) Add(key K, value V) bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Add", key, value)
 	ret0, _ := ret[0].(bool)
@@ -42,21 +47,87 @@ func (m *MockCache) Add(key string, value interface{}) bool {
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockCacheMockRecorder) Add(key, value interface{}) *gomock.Call {
+func (mr *MockCacheMockRecorder[K, V]) Add(key, value any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCache)(nil).Add), key, value)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCache[K, V])(nil).Add), key, value)
 }
 
 // Get mocks base method.
-func (m *MockCache) Get(key string) interface{} {
+func (m *MockCache[K, V]) Get(key K) (V, bool) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", key)
-	ret0, _ := ret[0].(interface{})
-	return ret0
+	ret0, _ := ret[0].(V)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCacheMockRecorder) Get(key interface{}) *gomock.Call {
+func (mr *MockCacheMockRecorder[K, V]) Get(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache[K, V])(nil).Get), key)
+}
+
+// Len mocks base method.
+func (m *MockCache[K, V]) Len() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Len")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Len indicates an expected call of Len.
+func (mr *MockCacheMockRecorder[K, V]) Len() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockCache[K, V])(nil).Len))
+}
+
+// Remove mocks base method.
+func (m *MockCache[K, V]) Remove(key K) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockCacheMockRecorder[K, V]) Remove(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockCache[K, V])(nil).Remove), key)
+}
+
+// MockSizer is a mock of Sizer interface.
+type MockSizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSizerMockRecorder
+}
+
+// MockSizerMockRecorder is the mock recorder for MockSizer.
+type MockSizerMockRecorder struct {
+	mock *MockSizer
+}
+
+// NewMockSizer creates a new mock instance.
+func NewMockSizer(ctrl *gomock.Controller) *MockSizer {
+	mock := &MockSizer{ctrl: ctrl}
+	mock.recorder = &MockSizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSizer) EXPECT() *MockSizerMockRecorder {
+	return m.recorder
+}
+
+// SizeEstimate mocks base method.
+func (m *MockSizer) SizeEstimate() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SizeEstimate")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// SizeEstimate indicates an expected call of SizeEstimate.
+func (mr *MockSizerMockRecorder) SizeEstimate() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SizeEstimate", reflect.TypeOf((*MockSizer)(nil).SizeEstimate))
 }
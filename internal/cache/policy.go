@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// WritePolicy decides which orders stored by the ingest path get into the cache
+type WritePolicy string
+
+const (
+	// WriteThrough caches every stored order
+	WriteThrough WritePolicy = "write-through"
+	// WriteAround leaves the cache to the read path
+	WriteAround WritePolicy = "write-around"
+	// WriteRecent caches only the most recently stored orders, in a tier of
+	// their own, so a flood of new orders doesn't push out the entries kept
+	// hot by the read path
+	WriteRecent WritePolicy = "write-recent"
+)
+
+// NewWriter wraps c for the ingest path, so Add follows the policy,
+// Get and Remove go straight to c. The read path reads through read,
+// which also finds the orders the policy keeps out of c
+func NewWriter[K comparable, V any](c Cache[K, V], policy WritePolicy, recent int) (ingest Cache[K, V], read Cache[K, V], err error) {
+	const op = "cache.NewWriter"
+
+	switch policy {
+	case WriteThrough:
+		return c, c, nil
+	case WriteAround:
+		return writeAround[K, V]{c}, c, nil
+	case WriteRecent:
+		if recent <= 0 {
+			return nil, nil, fmt.Errorf("%s: %s needs a positive number of recent orders", op, policy)
+		}
+		r := &recentTier[K, V]{
+			Cache:  c,
+			recent: New[K, V](recent),
+		}
+		return writeRecent[K, V]{r}, r, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown write policy %q", op, policy)
+	}
+}
+
+type writeAround[K comparable, V any] struct {
+	Cache[K, V]
+}
+
+func (w writeAround[K, V]) Add(key K, value V) bool {
+	return false
+}
+
+// recentTier keeps the orders stored by the ingest path apart from c,
+// bounded to the most recent ones, so they never push out what the read
+// path cached. Those the read path finds there are moved into c
+type recentTier[K comparable, V any] struct {
+	Cache[K, V]
+
+	recent *LocalCache[K, V]
+}
+
+func (r *recentTier[K, V]) Get(key K) (V, bool) {
+	if value, ok := r.Cache.Get(key); ok {
+		return value, true
+	}
+
+	value, ok := r.recent.Get(key)
+	if ok {
+		r.recent.Remove(key)
+		r.Cache.Add(key, value)
+	}
+
+	return value, ok
+}
+
+func (r *recentTier[K, V]) Remove(key K) bool {
+	removed := r.recent.Remove(key)
+	return r.Cache.Remove(key) || removed
+}
+
+// writeRecent adds to the recent tier only
+type writeRecent[K comparable, V any] struct {
+	*recentTier[K, V]
+}
+
+func (w writeRecent[K, V]) Add(key K, value V) bool {
+	return w.recent.Add(key, value)
+}
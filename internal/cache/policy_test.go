@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"fmt"
+	"slices"
+	"test-task/order-service/internal/cache"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WritePolicy(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		policy    cache.WritePolicy
+		recent    int
+		wantKeys  []string
+		wantErr   bool
+	}{
+		{
+			test_name: "Write-through",
+			policy:    cache.WriteThrough,
+			wantKeys:  []string{"order0", "order1", "order2", "order3"},
+		},
+		{
+			test_name: "Write-around",
+			policy:    cache.WriteAround,
+		},
+		{
+			test_name: "Most recent",
+			policy:    cache.WriteRecent,
+			recent:    2,
+			wantKeys:  []string{"order2", "order3"},
+		},
+		{
+			test_name: "Most recent without limit",
+			policy:    cache.WriteRecent,
+			wantErr:   true,
+		},
+		{
+			test_name: "Unknown policy",
+			policy:    "write-back",
+			wantErr:   true,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			c := cache.New[string, string](10)
+
+			// an entry added by the read path
+			c.Add("hot", "hot")
+
+			w, r, err := cache.NewWriter(c, tc.policy, tc.recent)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			for i := 0; i < 4; i++ {
+				key := fmt.Sprintf("order%d", i)
+				w.Add(key, key)
+			}
+
+			_, ok := r.Get("hot")
+			assert.True(t, ok)
+			for i := 0; i < 4; i++ {
+				key := fmt.Sprintf("order%d", i)
+				value, ok := r.Get(key)
+				assert.Equal(t, slices.Contains(tc.wantKeys, key), ok)
+				if ok {
+					assert.Equal(t, key, value)
+				}
+			}
+		})
+	}
+}
+
+func Test_WriteRecentKeepsReadEntries(t *testing.T) {
+	c := cache.New[string, string](10)
+
+	w, r, err := cache.NewWriter(c, cache.WriteRecent, 2)
+	assert.NoError(t, err)
+
+	w.Add("order0", "order0")
+
+	// read after ingest, the order is hot now
+	_, ok := r.Get("order0")
+	assert.True(t, ok)
+
+	for i := 1; i <= 2; i++ {
+		key := fmt.Sprintf("order%d", i)
+		w.Add(key, key)
+	}
+
+	value, ok := c.Get("order0")
+	assert.True(t, ok)
+	assert.Equal(t, "order0", value)
+
+	// the orders stored later stay out of the cache of the read path
+	assert.Equal(t, 1, c.Len())
+
+	// and removing an order takes it out of both
+	w.Remove("order2")
+	_, ok = r.Get("order2")
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"test-task/order-service/internal/metrics"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisTimeout = 100 * time.Millisecond
+
+// RedisConfig configures a Redis backed cache. Keys are stored under
+// Prefix and expire after TTL, zero TTL keeps them until Redis evicts
+// them. Each request is bounded by Timeout
+type RedisConfig struct {
+	Prefix  string
+	TTL     time.Duration
+	Timeout time.Duration
+}
+
+// Redis is a cache shared by the replicas, stored in a server speaking
+// the Redis protocol with values encoded as JSON. A failing server
+// degrades to misses, errors are logged and counted but not returned
+type Redis[K ~string, V any] struct {
+	log    *slog.Logger
+	client redis.UniversalClient
+	cfg    RedisConfig
+}
+
+func NewRedis[K ~string, V any](log *slog.Logger, client redis.UniversalClient, cfg RedisConfig) *Redis[K, V] {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultRedisTimeout
+	}
+
+	return &Redis[K, V]{
+		log:    log,
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+func (c *Redis[K, V]) Add(key K, value V) bool {
+	const op = "cache.Redis.Add"
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.fail(op, "add", key, fmt.Errorf("marshalling value: %w", err))
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.key(key), data, c.cfg.TTL).Err(); err != nil {
+		c.fail(op, "add", key, err)
+		return false
+	}
+
+	metrics.CacheRemoteRequests.WithLabelValues("add", "ok").Inc()
+	return true
+}
+
+func (c *Redis[K, V]) Get(key K) (V, bool) {
+	const op = "cache.Redis.Get"
+
+	var value V
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		metrics.CacheRemoteRequests.WithLabelValues("get", "miss").Inc()
+		return value, false
+	}
+	if err != nil {
+		c.fail(op, "get", key, err)
+		return value, false
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.fail(op, "get", key, fmt.Errorf("unmarshalling value: %w", err))
+		return value, false
+	}
+
+	metrics.CacheRemoteRequests.WithLabelValues("get", "hit").Inc()
+	return value, true
+}
+
+func (c *Redis[K, V]) Remove(key K) bool {
+	const op = "cache.Redis.Remove"
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.key(key)).Err(); err != nil {
+		c.fail(op, "remove", key, err)
+		return false
+	}
+
+	metrics.CacheRemoteRequests.WithLabelValues("remove", "ok").Inc()
+	return true
+}
+
+// Len counts the keys under the prefix. It scans the keyspace,
+// so it is meant for logs and diagnostics only
+func (c *Redis[K, V]) Len() int {
+	const op = "cache.Redis.Len"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*c.cfg.Timeout)
+	defer cancel()
+
+	n := 0
+	iter := c.client.Scan(ctx, 0, c.cfg.Prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		n++
+	}
+	if err := iter.Err(); err != nil {
+		c.log.Warn("failed counting cache keys", slog.String("op", op), slog.Any("error", err))
+	}
+
+	return n
+}
+
+func (c *Redis[K, V]) key(key K) string {
+	return c.cfg.Prefix + string(key)
+}
+
+func (c *Redis[K, V]) fail(op, request string, key K, err error) {
+	metrics.CacheRemoteRequests.WithLabelValues(request, "error").Inc()
+	c.log.Warn("cache request failed", slog.String("op", op), slog.String("key", string(key)), slog.Any("error", err))
+}
@@ -0,0 +1,97 @@
+package cache_test
+
+import (
+	"context"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return srv, client
+}
+
+func Test_Redis(t *testing.T) {
+	srv, client := newRedis(t)
+
+	c := cache.NewRedis[string, *domain.Order](logging.Discard(), client, cache.RedisConfig{
+		Prefix: "order:",
+		TTL:    time.Minute,
+	})
+
+	order := &domain.Order{OrderUid: "b563feb7b2b84b64c8w", Items: []domain.Item{{ChrtId: 9934930}}}
+
+	assert.True(t, c.Add(order.OrderUid, order))
+	assert.True(t, srv.Exists("order:"+order.OrderUid))
+	assert.Equal(t, 1, c.Len())
+
+	got, ok := c.Get(order.OrderUid)
+	assert.True(t, ok)
+	assert.Equal(t, order, got)
+
+	assert.True(t, c.Remove(order.OrderUid))
+	_, ok = c.Get(order.OrderUid)
+	assert.False(t, ok)
+
+	c.Add(order.OrderUid, order)
+	srv.FastForward(2 * time.Minute)
+	_, ok = c.Get(order.OrderUid)
+	assert.False(t, ok)
+
+	// a failing server degrades to misses
+	srv.Close()
+	assert.False(t, c.Add(order.OrderUid, order))
+	_, ok = c.Get(order.OrderUid)
+	assert.False(t, ok)
+}
+
+func Test_LayeredInvalidation(t *testing.T) {
+	_, client := newRedis(t)
+
+	log := logging.Discard()
+	shared := cache.NewRedis[string, string](log, client, cache.RedisConfig{Prefix: "order:"})
+
+	// two replicas sharing the L2 cache
+	l1a, l1b := cache.New[string, string](10), cache.New[string, string](10)
+	a := cache.NewLayered[string, string](log, l1a, shared, client, "invalidation")
+	b := cache.NewLayered[string, string](log, l1b, shared, client, "invalidation")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.RunInvalidation(ctx)
+	go b.RunInvalidation(ctx)
+
+	a.Add("k", "v")
+
+	// b misses its L1 and fills it from L2
+	value, ok := b.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "v", value)
+	assert.Equal(t, 1, l1b.Len())
+
+	// the subscriptions are established asynchronously
+	assert.Eventually(t, func() bool {
+		n, err := client.PubSubNumSub(ctx, "invalidation").Result()
+		return err == nil && n["invalidation"] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	a.Remove("k")
+
+	assert.Eventually(t, func() bool {
+		return l1b.Len() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, ok = b.Get("k")
+	assert.False(t, ok)
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Sharded spreads the entries over independent LocalCache segments by
+// the hash of the key, so lookups of different keys rarely contend on
+// the same mutex. Each segment holds its share of the capacity and of
+// the bytes budget and evicts on its own
+type Sharded[K ~string, V any] struct {
+	shards []*LocalCache[K, V]
+	ttl    time.Duration
+}
+
+func NewSharded[K ~string, V any](cfg Config, shards int) (*Sharded[K, V], error) {
+	const op = "cache.NewSharded"
+
+	if shards <= 0 {
+		return nil, fmt.Errorf("%s: number of shards must be positive, got %d", op, shards)
+	}
+
+	// rounding up, so the segments together hold at least the capacity
+	shardCfg := cfg
+	shardCfg.Capacity = (cfg.Capacity + shards - 1) / shards
+	shardCfg.MaxBytes = (cfg.MaxBytes + int64(shards) - 1) / int64(shards)
+
+	s := &Sharded[K, V]{
+		shards: make([]*LocalCache[K, V], shards),
+		ttl:    cfg.TTL,
+	}
+
+	for i := range s.shards {
+		shard, err := NewWithConfig[K, V](shardCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		s.shards[i] = shard
+	}
+
+	return s, nil
+}
+
+func (s *Sharded[K, V]) Add(key K, value V) bool {
+	return s.shard(key).Add(key, value)
+}
+
+func (s *Sharded[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+// Restore adds an entry saved from a cache, keeping its expiry
+func (s *Sharded[K, V]) Restore(item Item[K, V]) bool {
+	return s.shard(item.Key).Restore(item)
+}
+
+func (s *Sharded[K, V]) Remove(key K) bool {
+	return s.shard(key).Remove(key)
+}
+
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Stats sums the stats of the segments, which are taken one by one
+// and so don't form a consistent snapshot under concurrent use
+func (s *Sharded[K, V]) Stats() Stats {
+	var total Stats
+	for _, shard := range s.shards {
+		stats := shard.Stats()
+		total.Len += stats.Len
+		total.Bytes += stats.Bytes
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+		total.Expirations += stats.Expirations
+	}
+	return total
+}
+
+// RemoveExpired drops all entries past their TTL and returns their count
+func (s *Sharded[K, V]) RemoveExpired() int {
+	removed := 0
+	for _, shard := range s.shards {
+		removed += shard.RemoveExpired()
+	}
+	return removed
+}
+
+// RunExpiry removes expired entries every interval until ctx is done
+func (s *Sharded[K, V]) RunExpiry(ctx context.Context, interval time.Duration) {
+	if s.ttl <= 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RemoveExpired()
+		}
+	}
+}
+
+// Range calls fn for the entries that have not expired, segment by
+// segment, until fn returns false. The cache must not be modified from fn
+func (s *Sharded[K, V]) Range(fn func(item Item[K, V]) bool) {
+	more := true
+	for _, shard := range s.shards {
+		shard.Range(func(item Item[K, V]) bool {
+			more = fn(item)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// shard picks the segment by the FNV-1a hash of the key,
+// computed inline to keep lookups free of allocations
+func (s *Sharded[K, V]) shard(key K) *LocalCache[K, V] {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return s.shards[h%uint32(len(s.shards))]
+}
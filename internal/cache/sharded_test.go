@@ -0,0 +1,102 @@
+package cache_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"test-task/order-service/internal/cache"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sharded(t *testing.T) {
+	c, err := cache.NewSharded[string, int](cache.Config{Capacity: 64}, 8)
+	assert.NoError(t, err)
+
+	for i := 0; i < 32; i++ {
+		c.Add(fmt.Sprintf("order%d", i), i)
+	}
+
+	assert.Equal(t, 32, c.Len())
+	for i := 0; i < 32; i++ {
+		value, ok := c.Get(fmt.Sprintf("order%d", i))
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+
+	c.Remove("order0")
+	_, ok := c.Get("order0")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 31, stats.Len)
+	assert.Equal(t, int64(32), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+
+	seen := 0
+	c.Range(func(cache.Item[string, int]) bool {
+		seen++
+		return seen < 10
+	})
+	assert.Equal(t, 10, seen)
+
+	_, err = cache.NewSharded[string, int](cache.Config{Capacity: 64}, 0)
+	assert.Error(t, err)
+}
+
+// Test_ShardedStress hammers the cache from many goroutines,
+// it is meant to be run with the race detector
+func Test_ShardedStress(t *testing.T) {
+	const (
+		capacity   = 256
+		shards     = 16
+		goroutines = 32
+		ops        = 5000
+	)
+
+	for _, policy := range policies {
+		policy := policy
+
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			c, err := cache.NewSharded[string, int](cache.Config{Capacity: capacity, Eviction: policy}, shards)
+			assert.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+
+					r := rand.New(rand.NewSource(seed))
+					for i := 0; i < ops; i++ {
+						key := fmt.Sprintf("order%d", r.Intn(4*capacity))
+						switch op := r.Intn(10); {
+						case op < 6:
+							if value, ok := c.Get(key); ok && value != len(key) {
+								t.Errorf("got %d for %s", value, key)
+							}
+						case op < 9:
+							c.Add(key, len(key))
+						default:
+							c.Remove(key)
+						}
+					}
+
+					c.Stats()
+					c.Range(func(cache.Item[string, int]) bool { return true })
+				}(int64(g))
+			}
+			wg.Wait()
+
+			// each segment holds its share rounded up
+			assert.LessOrEqual(t, c.Len(), capacity)
+
+			stats := c.Stats()
+			assert.Equal(t, c.Len(), stats.Len)
+			assert.Greater(t, stats.Hits, int64(0))
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A snapshot is a fixed header followed by the entries as JSON lines:
+//
+//	magic    [8]byte  "ORDCACHE"
+//	version  uint32
+//	entries  uint32
+//	checksum uint32   CRC-32C of the entries
+//	length   uint64   length of the entries in bytes
+//
+// with the numbers in big endian
+const (
+	snapshotMagic   = "ORDCACHE"
+	snapshotVersion = 1
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+type snapshotHeader struct {
+	Magic    [8]byte
+	Version  uint32
+	Entries  uint32
+	Checksum uint32
+	Length   uint64
+}
+
+type snapshotEntry[K comparable, V any] struct {
+	Key       K         `json:"key"`
+	Value     V         `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Snapshotter is a cache that can be saved to a snapshot and restored
+type Snapshotter[K comparable, V any] interface {
+	Range(fn func(item Item[K, V]) bool)
+	Restore(item Item[K, V]) bool
+}
+
+// WriteSnapshot saves the entries of c to path, returning their number.
+// The snapshot is written next to path and renamed over it once synced,
+// so a crash midway leaves the previous snapshot in place
+func WriteSnapshot[K comparable, V any](path string, c Snapshotter[K, V]) (int, error) {
+	const op = "cache.WriteSnapshot"
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+
+	var (
+		count int
+		err   error
+	)
+	c.Range(func(item Item[K, V]) bool {
+		err = enc.Encode(snapshotEntry[K, V]{Key: item.Key, Value: item.Value, ExpiresAt: item.ExpiresAt})
+		count++
+		return err == nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: encoding entry: %w", op, err)
+	}
+
+	header := snapshotHeader{
+		Version:  snapshotVersion,
+		Entries:  uint32(count),
+		Checksum: crc32.Checksum(body.Bytes(), castagnoli),
+		Length:   uint64(body.Len()),
+	}
+	copy(header.Magic[:], snapshotMagic)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("%s: creating directory: %w", op, err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, fmt.Errorf("%s: creating file: %w", op, err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := binary.Write(f, binary.BigEndian, header); err != nil {
+		return 0, fmt.Errorf("%s: writing header: %w", op, err)
+	}
+
+	if _, err := body.WriteTo(f); err != nil {
+		return 0, fmt.Errorf("%s: writing entries: %w", op, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("%s: syncing file: %w", op, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("%s: closing file: %w", op, err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, fmt.Errorf("%s: replacing snapshot: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// ReadSnapshot restores the entries saved to path into c, returning the
+// number restored, expired ones are skipped. Nothing is restored unless
+// the whole snapshot is intact. A missing snapshot is an os.ErrNotExist
+func ReadSnapshot[K comparable, V any](path string, c Snapshotter[K, V]) (int, error) {
+	const op = "cache.ReadSnapshot"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	var header snapshotHeader
+	if err := binary.Read(f, binary.BigEndian, &header); err != nil {
+		return 0, fmt.Errorf("%s: reading header: %w", op, err)
+	}
+
+	if string(header.Magic[:]) != snapshotMagic {
+		return 0, fmt.Errorf("%s: not a cache snapshot", op)
+	}
+
+	if header.Version != snapshotVersion {
+		return 0, fmt.Errorf("%s: unsupported snapshot version %d", op, header.Version)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(f, int64(header.Length)+1))
+	if err != nil {
+		return 0, fmt.Errorf("%s: reading entries: %w", op, err)
+	}
+
+	if uint64(len(body)) != header.Length {
+		return 0, fmt.Errorf("%s: snapshot is %d bytes long, header says %d", op, len(body), header.Length)
+	}
+
+	if crc32.Checksum(body, castagnoli) != header.Checksum {
+		return 0, fmt.Errorf("%s: checksum mismatch", op)
+	}
+
+	// decoding everything first, so a broken snapshot restores nothing
+	entries := make([]snapshotEntry[K, V], 0, header.Entries)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var entry snapshotEntry[K, V]
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("%s: decoding entry: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != int(header.Entries) {
+		return 0, fmt.Errorf("%s: snapshot has %d entries, header says %d", op, len(entries), header.Entries)
+	}
+
+	restored := 0
+	for _, entry := range entries {
+		if c.Restore(Item[K, V]{Key: entry.Key, Value: entry.Value, ExpiresAt: entry.ExpiresAt}) {
+			restored++
+		}
+	}
+
+	return restored, nil
+}
+
+// RunSnapshots writes a snapshot of c to path every interval until ctx
+// is done, so that a crash loses at most interval worth of entries
+func RunSnapshots[K comparable, V any](ctx context.Context, log *slog.Logger, path string, interval time.Duration, c Snapshotter[K, V]) {
+	const op = "cache.RunSnapshots"
+
+	if interval <= 0 {
+		return
+	}
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := WriteSnapshot(path, c)
+			if err != nil {
+				log.Error("failed writing cache snapshot", slog.Any("error", err))
+				continue
+			}
+			log.Debug("cache snapshot written", slog.Int("count", count))
+		}
+	}
+}
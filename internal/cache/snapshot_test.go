@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	src, err := cache.NewSharded[string, *domain.Order](cache.Config{Capacity: 10, TTL: time.Hour}, 4)
+	assert.NoError(t, err)
+
+	for _, uid := range []string{"b563feb7b2b84b64c8w", "9650f7fa5b404c2f996"} {
+		src.Add(uid, &domain.Order{OrderUid: uid, Items: []domain.Item{{ChrtId: 9934930}}})
+	}
+
+	count, err := cache.WriteSnapshot(path, src)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	dst, err := cache.NewSharded[string, *domain.Order](cache.Config{Capacity: 10, TTL: time.Hour}, 2)
+	assert.NoError(t, err)
+
+	count, err = cache.ReadSnapshot(path, dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	got, ok := dst.Get("b563feb7b2b84b64c8w")
+	assert.True(t, ok)
+	assert.Equal(t, "b563feb7b2b84b64c8w", got.OrderUid)
+	assert.Len(t, got.Items, 1)
+
+	// expiry is kept rather than restarted
+	src.Range(func(want cache.Item[string, *domain.Order]) bool {
+		dst.Range(func(got cache.Item[string, *domain.Order]) bool {
+			if got.Key == want.Key {
+				assert.True(t, want.ExpiresAt.Equal(got.ExpiresAt))
+			}
+			return true
+		})
+		return true
+	})
+}
+
+func Test_SnapshotSkipsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	src, err := cache.NewWithConfig[string, string](cache.Config{Capacity: 10, TTL: 20 * time.Millisecond})
+	assert.NoError(t, err)
+	src.Add("k", "v")
+
+	_, err = cache.WriteSnapshot(path, src)
+	assert.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+
+	dst := cache.New[string, string](10)
+	count, err := cache.ReadSnapshot(path, dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, dst.Len())
+}
+
+func Test_SnapshotBroken(t *testing.T) {
+	dir := t.TempDir()
+
+	src := cache.New[string, string](10)
+	src.Add("k0", "v0")
+	src.Add("k1", "v1")
+
+	valid := filepath.Join(dir, "valid.snapshot")
+	_, err := cache.WriteSnapshot(valid, src)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(valid)
+	assert.NoError(t, err)
+
+	corrupt := func(offset int) []byte {
+		broken := append([]byte(nil), data...)
+		broken[offset] ^= 0xff
+		return broken
+	}
+
+	test_cases := []struct {
+		test_name string
+		data      []byte
+	}{
+		{
+			test_name: "Bad magic",
+			data:      corrupt(0),
+		},
+		{
+			test_name: "Unsupported version",
+			data:      corrupt(11),
+		},
+		{
+			test_name: "Checksum mismatch",
+			data:      corrupt(len(data) - 3),
+		},
+		{
+			test_name: "Truncated",
+			data:      data[:len(data)-5],
+		},
+		{
+			test_name: "Truncated header",
+			data:      data[:10],
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.test_name)
+			assert.NoError(t, os.WriteFile(path, tc.data, 0o644))
+
+			dst := cache.New[string, string](10)
+			_, err := cache.ReadSnapshot(path, dst)
+			assert.Error(t, err)
+			assert.Equal(t, 0, dst.Len())
+		})
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := cache.ReadSnapshot(filepath.Join(dir, "missing"), cache.New[string, string](10))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
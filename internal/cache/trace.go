@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Recording writes the key of every lookup of the wrapped cache to a trace,
+// one per line. Traces recorded from the read path and copied to
+// internal/cache/testdata/traces/<name>.trace are replayed by
+// Benchmark_EvictionPolicies along with the synthetic ones
+type Recording[K comparable, V any] struct {
+	Cache[K, V]
+
+	mu  sync.Mutex
+	w   *bufio.Writer
+	out io.WriteCloser
+	err error
+}
+
+func NewRecording[K comparable, V any](c Cache[K, V], out io.WriteCloser) *Recording[K, V] {
+	return &Recording[K, V]{
+		Cache: c,
+		w:     bufio.NewWriter(out),
+		out:   out,
+	}
+}
+
+func (r *Recording[K, V]) Get(key K) (V, bool) {
+	r.mu.Lock()
+	if r.err == nil {
+		_, r.err = fmt.Fprintln(r.w, key)
+	}
+	r.mu.Unlock()
+
+	return r.Cache.Get(key)
+}
+
+// Close flushes the trace and closes its output, reporting
+// the first write that failed, lookups after it aren't recorded
+func (r *Recording[K, V]) Close() error {
+	const op = "cache.Recording.Close"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+
+	if err := errors.Join(r.err, r.out.Close()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
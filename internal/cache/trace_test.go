@@ -0,0 +1,36 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"test-task/order-service/internal/cache"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordingWritesLookups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookups.trace")
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+
+	c := cache.New[string, string](10)
+	c.Add("b563feb7b2b84b64c8w", "order")
+
+	r := cache.NewRecording[string, string](c, f)
+
+	value, ok := r.Get("b563feb7b2b84b64c8w")
+	assert.True(t, ok)
+	assert.Equal(t, "order", value)
+
+	_, ok = r.Get("9650f7fa5b404c2f999")
+	assert.False(t, ok)
+
+	assert.NoError(t, r.Close())
+
+	// the format read back by the eviction benchmark
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "b563feb7b2b84b64c8w\n9650f7fa5b404c2f999\n", string(data))
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"regexp"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -10,10 +14,142 @@ import (
 
 const configFile = "data/config.yaml"
 
+const (
+	defaultLogLevel  = "info"
+	defaultLogFormat = "json"
+
+	defaultShutdownTimeout = 10 * time.Second
+	defaultShutdownDelay   = 5 * time.Second
+
+	defaultDeadLetterChannel = "order-notification.dlq"
+	defaultMaxAttempts       = 5
+	defaultWorkers           = 4
+	defaultPartitionKey      = "order_uid"
+	defaultBatchSize         = 1
+	defaultBatchTimeout      = 20 * time.Millisecond
+
+	defaultCacheCapacity         = 200
+	defaultCacheWritePolicy      = "write-through"
+	defaultCacheEviction         = "lru"
+	defaultCacheShards           = 16
+	defaultCacheRecent           = 100
+	defaultCacheTTL              = 10 * time.Minute
+	defaultCacheMaxBytes         = 64 << 20
+	defaultCacheExpiry           = time.Minute
+	defaultCacheNotFoundTTL      = 2 * time.Second
+	defaultCacheBackend          = "memory"
+	defaultCacheSnapshotPath     = "data/cache.snapshot"
+	defaultCacheSnapshotInterval = 5 * time.Minute
+	defaultWarmupMode            = "snapshot"
+	defaultWarmupStatsInterval   = time.Minute
+	defaultWarmupStatsRetention  = 30 * 24 * time.Hour
+
+	defaultRedisAddr         = "localhost:6379"
+	defaultRedisPrefix       = "order:"
+	defaultRedisTimeout      = 100 * time.Millisecond
+	defaultRedisInvalidation = "order-cache-invalidation"
+
+	defaultClusterID     = "dev"
+	defaultClientPrefix  = "order-service"
+	defaultDurableName   = "order-service"
+	defaultQueueGroup    = "order-service"
+	defaultChannel       = "order-notification"
+	defaultStatusChannel = "order-status"
+	defaultMaxInflight   = 25
+	defaultAckWait       = 60 * time.Second
+
+	defaultIdempotencyKeyTTL        = 24 * time.Hour
+	defaultIdempotencyClaimTimeout  = time.Minute
+	defaultIdempotencyPurgeInterval = time.Hour
+)
+
 type Config struct {
-	DSN        string `yaml:"dsn"`
-	NATSAddr   string `yaml:"nats_addr"`
-	HTTPServer `yaml:"http_server"`
+	DSN               string        `yaml:"dsn"`
+	NATSAddr          string        `yaml:"nats_addr"`
+	DeadLetterChannel string        `yaml:"dead_letter_channel"`
+	MaxAttempts       int           `yaml:"max_delivery_attempts"`
+	Workers           int           `yaml:"workers"`
+	PartitionKey      string        `yaml:"partition_key"`
+	BatchSize         int           `yaml:"batch_size"`
+	BatchTimeout      time.Duration `yaml:"batch_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay"`
+	Log               Log           `yaml:"log"`
+	Cache             Cache         `yaml:"cache"`
+	Idempotency       Idempotency   `yaml:"idempotency"`
+	HTTPServer        `yaml:"http_server"`
+	NATSStreaming     `yaml:"nats_streaming"`
+}
+
+// Log sets the minimal level (debug, info, warn, error)
+// and the format (json, text) of the service logs
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Idempotency configures the keys of POST /orders. A key is kept for
+// KeyTTL, purged every PurgeInterval, and a request still in progress
+// after ClaimTimeout is taken for abandoned, so its key can be claimed again
+type Idempotency struct {
+	KeyTTL        time.Duration `yaml:"key_ttl"`
+	ClaimTimeout  time.Duration `yaml:"claim_timeout"`
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// Cache configures the order cache. Backend is memory, redis or layered,
+// the latter keeping the in-memory cache in front of Redis. The memory
+// one is split into Shards segments sharing the capacity. Eviction
+// picks the entries evicted when a segment is full: lru, lfu, 2q or arc.
+// WritePolicy decides which orders stored by the ingest path are cached:
+// write-through, write-around or write-recent, caching only the Recent
+// most recently stored ones.
+// Entries live for TTL, expired ones are swept every ExpiryInterval,
+// and the estimated size of the cached orders is kept under MaxBytes.
+// Orders found missing are remembered for NotFoundTTL, negative disables it,
+// an order stored by another instance may be answered as missing that long.
+// The in-memory cache is saved to SnapshotPath every SnapshotInterval
+// and on shutdown, and restored from there on start.
+// When TracePath is set, the orders looked up are appended there,
+// recording a trace for the eviction benchmark
+type Cache struct {
+	Capacity         int           `yaml:"capacity"`
+	Eviction         string        `yaml:"eviction"`
+	Shards           int           `yaml:"shards"`
+	WritePolicy      string        `yaml:"write_policy"`
+	Recent           int           `yaml:"recent"`
+	TTL              time.Duration `yaml:"ttl"`
+	MaxBytes         int64         `yaml:"max_bytes"`
+	ExpiryInterval   time.Duration `yaml:"expiry_interval"`
+	NotFoundTTL      time.Duration `yaml:"not_found_ttl"`
+	Backend          string        `yaml:"backend"`
+	SnapshotPath     string        `yaml:"snapshot_path"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+	TracePath        string        `yaml:"trace_path"`
+	Warmup           Warmup        `yaml:"warmup"`
+	Redis            Redis         `yaml:"redis"`
+}
+
+// Warmup fills the cache on start with up to Orders orders. Mode is
+// snapshot, recent (by date_created), frequent (by the access stats
+// recorded every StatsInterval) or none. Stats of the orders not looked
+// up for StatsRetention are purged
+type Warmup struct {
+	Mode           string        `yaml:"mode"`
+	Orders         int           `yaml:"orders"`
+	StatsInterval  time.Duration `yaml:"stats_interval"`
+	StatsRetention time.Duration `yaml:"stats_retention"`
+}
+
+// Redis locates the shared cache. Keys are stored under Prefix, and
+// the layered backend publishes removals on InvalidationChannel
+type Redis struct {
+	Addr                string        `yaml:"addr"`
+	Password            string        `yaml:"password"`
+	DB                  int           `yaml:"db"`
+	Prefix              string        `yaml:"prefix"`
+	Timeout             time.Duration `yaml:"timeout"`
+	InvalidationChannel string        `yaml:"invalidation_channel"`
 }
 
 type HTTPServer struct {
@@ -21,6 +157,21 @@ type HTTPServer struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+// NATSStreaming configures the order subscription. Replicas sharing
+// the same queue group split the channel load, and a durable name
+// lets them resume from the last acked message after a restart
+type NATSStreaming struct {
+	ClusterID     string        `yaml:"cluster_id"`
+	ClientID      string        `yaml:"client_id"`
+	Channel       string        `yaml:"channel"`
+	StatusChannel string        `yaml:"status_channel"`
+	DurableName   string        `yaml:"durable_name"`
+	QueueGroup    string        `yaml:"queue_group"`
+	MaxInflight   int           `yaml:"max_inflight"`
+	AckWait       time.Duration `yaml:"ack_wait"`
+	MaxLag        time.Duration `yaml:"max_lag"`
+}
+
 type Service struct {
 	config Config
 }
@@ -51,6 +202,238 @@ func (s Service) NATSAddr() string {
 	return s.config.NATSAddr
 }
 
+func (s Service) LogLevel() string {
+	if s.config.Log.Level == "" {
+		return defaultLogLevel
+	}
+	return s.config.Log.Level
+}
+
+func (s Service) LogFormat() string {
+	if s.config.Log.Format == "" {
+		return defaultLogFormat
+	}
+	return s.config.Log.Format
+}
+
+// ShutdownTimeout bounds the whole graceful shutdown
+func (s Service) ShutdownTimeout() time.Duration {
+	if s.config.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return s.config.ShutdownTimeout
+}
+
+// ShutdownDelay is how long the server keeps answering after the readiness
+// probe turns not ready, for load balancers to notice. Negative disables it
+func (s Service) ShutdownDelay() time.Duration {
+	switch {
+	case s.config.ShutdownDelay < 0:
+		return 0
+	case s.config.ShutdownDelay == 0:
+		return defaultShutdownDelay
+	}
+	return s.config.ShutdownDelay
+}
+
+func (s Service) DeadLetterChannel() string {
+	if s.config.DeadLetterChannel == "" {
+		return defaultDeadLetterChannel
+	}
+	return s.config.DeadLetterChannel
+}
+
+// MaxAttempts is the number of deliveries of a failing message before it is dead-lettered
+func (s Service) MaxAttempts() int {
+	if s.config.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return s.config.MaxAttempts
+}
+
+// Workers is the number of messages handled in parallel
+func (s Service) Workers() int {
+	if s.config.Workers <= 0 {
+		return defaultWorkers
+	}
+	return s.config.Workers
+}
+
+// PartitionKey is the message field keeping related messages in order,
+// either order_uid or customer_id
+func (s Service) PartitionKey() string {
+	if s.config.PartitionKey == "" {
+		return defaultPartitionKey
+	}
+	return s.config.PartitionKey
+}
+
+// BatchSize is the number of orders saved in one transaction, 1 disables batching.
+// Workers wait for their batch to commit, so batches don't grow past Workers
+func (s Service) BatchSize() int {
+	if s.config.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+	return s.config.BatchSize
+}
+
+// BatchTimeout is how long a batch waits to fill up before it is saved
+func (s Service) BatchTimeout() time.Duration {
+	if s.config.BatchTimeout <= 0 {
+		return defaultBatchTimeout
+	}
+	return s.config.BatchTimeout
+}
+
+// Cache returns the cache settings with defaults applied
+func (s Service) Cache() Cache {
+	c := s.config.Cache
+
+	if c.Capacity <= 0 {
+		c.Capacity = defaultCacheCapacity
+	}
+	if c.Eviction == "" {
+		c.Eviction = defaultCacheEviction
+	}
+	if c.Shards <= 0 {
+		c.Shards = defaultCacheShards
+	}
+	if c.WritePolicy == "" {
+		c.WritePolicy = defaultCacheWritePolicy
+	}
+	if c.Recent <= 0 {
+		c.Recent = defaultCacheRecent
+	}
+	if c.TTL <= 0 {
+		c.TTL = defaultCacheTTL
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultCacheMaxBytes
+	}
+	if c.ExpiryInterval <= 0 {
+		c.ExpiryInterval = defaultCacheExpiry
+	}
+	if c.NotFoundTTL == 0 {
+		c.NotFoundTTL = defaultCacheNotFoundTTL
+	}
+	if c.Backend == "" {
+		c.Backend = defaultCacheBackend
+	}
+	if c.SnapshotPath == "" {
+		c.SnapshotPath = defaultCacheSnapshotPath
+	}
+	if c.SnapshotInterval <= 0 {
+		c.SnapshotInterval = defaultCacheSnapshotInterval
+	}
+	if c.Warmup.Mode == "" {
+		c.Warmup.Mode = defaultWarmupMode
+	}
+	if c.Warmup.Orders <= 0 {
+		c.Warmup.Orders = c.Capacity
+	}
+	if c.Warmup.StatsInterval <= 0 {
+		c.Warmup.StatsInterval = defaultWarmupStatsInterval
+	}
+	if c.Warmup.StatsRetention <= 0 {
+		c.Warmup.StatsRetention = defaultWarmupStatsRetention
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = defaultRedisAddr
+	}
+	if c.Redis.Prefix == "" {
+		c.Redis.Prefix = defaultRedisPrefix
+	}
+	if c.Redis.Timeout <= 0 {
+		c.Redis.Timeout = defaultRedisTimeout
+	}
+	if c.Redis.InvalidationChannel == "" {
+		c.Redis.InvalidationChannel = defaultRedisInvalidation
+	}
+
+	return c
+}
+
+// Idempotency returns the idempotency key settings with defaults applied
+func (s Service) Idempotency() Idempotency {
+	i := s.config.Idempotency
+
+	if i.KeyTTL <= 0 {
+		i.KeyTTL = defaultIdempotencyKeyTTL
+	}
+	if i.ClaimTimeout <= 0 {
+		i.ClaimTimeout = defaultIdempotencyClaimTimeout
+	}
+	if i.PurgeInterval <= 0 {
+		i.PurgeInterval = defaultIdempotencyPurgeInterval
+	}
+
+	return i
+}
+
+// Streaming returns the NATS Streaming settings with defaults applied.
+// Client IDs must be unique within the cluster, so unless set explicitly
+// the ID is the host name of the instance with a random suffix, as
+// replicas may share a host. A durable queue subscription is kept by
+// the server under its queue group and durable name, not the client ID,
+// so both have defaults and a restarted instance resumes where it stopped
+func (s Service) Streaming() NATSStreaming {
+	ns := s.config.NATSStreaming
+
+	if ns.ClusterID == "" {
+		ns.ClusterID = defaultClusterID
+	}
+	if ns.ClientID == "" {
+		ns.ClientID = instanceClientID()
+	}
+	if ns.Channel == "" {
+		ns.Channel = defaultChannel
+	}
+	if ns.DurableName == "" {
+		ns.DurableName = defaultDurableName
+	}
+	if ns.QueueGroup == "" {
+		ns.QueueGroup = defaultQueueGroup
+	}
+	if ns.StatusChannel == "" {
+		ns.StatusChannel = defaultStatusChannel
+	}
+	if ns.MaxInflight <= 0 {
+		ns.MaxInflight = defaultMaxInflight
+	}
+	if ns.AckWait <= 0 {
+		ns.AckWait = defaultAckWait
+	}
+	// a message waiting longer than AckWait gets redelivered anyway
+	if ns.MaxLag <= 0 {
+		ns.MaxLag = ns.AckWait
+	}
+
+	return ns
+}
+
+var invalidClientIDChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// instanceClientID is generated once, so the instance keeps its ID
+var instanceClientID = sync.OnceValue(func() string {
+	host, _ := os.Hostname()
+	return newClientID(host)
+})
+
+// newClientID returns a client ID for the host, unique even among the
+// instances running on the same host
+func newClientID(host string) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d-%d", defaultClientPrefix, os.Getpid(), time.Now().UnixNano())
+	}
+
+	if host == "" {
+		return defaultClientPrefix + "-" + hex.EncodeToString(suffix)
+	}
+
+	return defaultClientPrefix + "-" + invalidClientIDChars.ReplaceAllString(host, "_") + "-" + hex.EncodeToString(suffix)
+}
+
 func (s Service) HTTPAddr() string {
 	return s.config.HTTPServer.Address
 }
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StreamingDefaults(t *testing.T) {
+	test_cases := []struct {
+		test_name   string
+		config      NATSStreaming
+		wantDurable string
+		wantQueue   string
+		wantClient  *regexp.Regexp
+	}{
+		{
+			test_name:   "Defaults",
+			wantDurable: defaultDurableName,
+			wantQueue:   defaultQueueGroup,
+			wantClient:  regexp.MustCompile(`^order-service-[a-zA-Z0-9_-]+-[0-9a-f]{8}$`),
+		},
+		{
+			test_name: "Explicit settings are kept",
+			config: NATSStreaming{
+				ClientID:    "replica-1",
+				DurableName: "orders",
+				QueueGroup:  "order-workers",
+			},
+			wantDurable: "orders",
+			wantQueue:   "order-workers",
+			wantClient:  regexp.MustCompile(`^replica-1$`),
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			s := Service{config: Config{NATSStreaming: tc.config}}
+
+			ns := s.Streaming()
+
+			assert.Equal(t, tc.wantDurable, ns.DurableName)
+			assert.Equal(t, tc.wantQueue, ns.QueueGroup)
+			assert.Regexp(t, tc.wantClient, ns.ClientID)
+		})
+	}
+}
+
+func Test_InstanceClientID(t *testing.T) {
+	s := Service{}
+
+	// the instance keeps its ID for the subscriptions it makes
+	assert.Equal(t, s.Streaming().ClientID, s.Streaming().ClientID)
+}
+
+func Test_NewClientID(t *testing.T) {
+	first := newClientID("node-1.example.com")
+	second := newClientID("node-1.example.com")
+
+	// replicas on the same host get different IDs
+	assert.NotEqual(t, first, second)
+	assert.Regexp(t, `^order-service-node-1_example_com-[0-9a-f]{8}$`, first)
+
+	assert.Regexp(t, `^order-service-[0-9a-f]{8}$`, newClientID(""))
+}
+
+func Test_ShutdownDelay(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		delay     time.Duration
+		want      time.Duration
+	}{
+		{
+			test_name: "Default",
+			want:      defaultShutdownDelay,
+		},
+		{
+			test_name: "Explicit delay",
+			delay:     time.Second,
+			want:      time.Second,
+		},
+		{
+			test_name: "Negative disables the delay",
+			delay:     -1,
+			want:      0,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			s := Service{config: Config{ShutdownDelay: tc.delay}}
+
+			assert.Equal(t, tc.want, s.ShutdownDelay())
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"test-task/order-service/internal/domain"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+// Queue forwards failed messages to the dead-letter channel and keeps
+// them in storage, so they can be listed and replayed later
+type Queue struct {
+	log     *slog.Logger
+	pub     nats_streaming.Publisher
+	store   storage.DeadLetterStorage
+	channel string
+}
+
+func New(log *slog.Logger, pub nats_streaming.Publisher, store storage.DeadLetterStorage, channel string) *Queue {
+	return &Queue{
+		log:     log,
+		pub:     pub,
+		store:   store,
+		channel: channel,
+	}
+}
+
+// Put records the failed message with the error that caused the failure.
+// The message is stored before it is published, so when either fails it is
+// left for redelivery, and a redelivered one stored already is only published
+func (q *Queue) Put(ctx context.Context, msg nats_streaming.Message, reason error) error {
+	const op = "deadletter.Put"
+
+	dl := &domain.DeadLetter{
+		Channel:  msg.Subject(),
+		Sequence: msg.Sequence(),
+		Attempts: msg.Attempt(),
+		Reason:   reason.Error(),
+		Data:     msg.Data(),
+		FailedAt: time.Now(),
+	}
+
+	if err := q.store.SaveDeadLetter(ctx, dl); err != nil && !errors.Is(err, storage.ErrEntryAlreadyExists) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("%s: marshalling dead letter: %w", op, err)
+	}
+
+	if err := q.pub.Publish(q.channel, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	q.log.InfoContext(ctx, "message moved to dead-letter channel", slog.String("dead_letter_channel", q.channel))
+
+	return nil
+}
+
+func (q *Queue) List(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error) {
+	const op = "deadletter.List"
+
+	res, err := q.store.ListDeadLetters(ctx, afterId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// Replay publishes the original message back to its channel and forgets the dead letter
+func (q *Queue) Replay(ctx context.Context, id int64) error {
+	const op = "deadletter.Replay"
+
+	dl, err := q.store.GetDeadLetter(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := q.pub.Publish(dl.Channel, dl.Data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := q.store.DeleteDeadLetter(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	q.log.InfoContext(ctx, "dead letter replayed", slog.Int64("id", id), slog.String("channel", dl.Channel))
+
+	return nil
+}
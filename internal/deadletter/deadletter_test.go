@@ -0,0 +1,196 @@
+package deadletter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"test-task/order-service/internal/deadletter"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	mock_nats_streaming "test-task/order-service/internal/nats-streaming/mocks"
+	"test-task/order-service/internal/storage"
+	mock_storage "test-task/order-service/internal/storage/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const dlqChannel = "order-notification.dlq"
+
+var errPublish = errors.New("publish failed")
+
+func Test_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pub := mock_nats_streaming.NewMockPublisher(ctrl)
+	store := mock_storage.NewMockDeadLetterStorage(ctrl)
+
+	payload := []byte(`{"order_uid":`)
+
+	msg := mock_nats_streaming.NewMockMessage(ctrl)
+	msg.EXPECT().Subject().Return("order-notification").AnyTimes()
+	msg.EXPECT().Sequence().Return(uint64(42)).AnyTimes()
+	msg.EXPECT().Attempt().Return(3).AnyTimes()
+	msg.EXPECT().Data().Return(payload).AnyTimes()
+
+	store.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, dl *domain.DeadLetter) error {
+			dl.Id = 7
+			return nil
+		})
+
+	pub.EXPECT().Publish(dlqChannel, gomock.Any()).DoAndReturn(
+		func(_ string, data []byte) error {
+			var dl domain.DeadLetter
+			assert.NoError(t, json.Unmarshal(data, &dl))
+
+			assert.Equal(t, int64(7), dl.Id)
+			assert.Equal(t, "order-notification", dl.Channel)
+			assert.Equal(t, uint64(42), dl.Sequence)
+			assert.Equal(t, 3, dl.Attempts)
+			assert.Equal(t, "invalid data", dl.Reason)
+			assert.Equal(t, payload, dl.Data)
+			return nil
+		})
+
+	q := deadletter.New(logging.Discard(), pub, store, dlqChannel)
+
+	assert.NoError(t, q.Put(context.Background(), msg, errors.New("invalid data")))
+}
+
+func Test_PutFailures(t *testing.T) {
+	errStore := errors.New("connection refused")
+
+	test_cases := []struct {
+		test_name string
+		wantErr   error
+		prepare   func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage)
+	}{
+		{
+			test_name: "Store failed, nothing is published",
+			wantErr:   errStore,
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				store.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(errStore)
+			},
+		},
+		{
+			test_name: "Publish failed",
+			wantErr:   errPublish,
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				gomock.InOrder(
+					store.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(nil),
+					pub.EXPECT().Publish(dlqChannel, gomock.Any()).Return(errPublish),
+				)
+			},
+		},
+		{
+			test_name: "Redelivered after publish failed, stored already",
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				gomock.InOrder(
+					store.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, dl *domain.DeadLetter) error {
+							dl.Id = 7
+							return storage.ErrEntryAlreadyExists
+						}),
+					pub.EXPECT().Publish(dlqChannel, gomock.Any()).DoAndReturn(
+						func(_ string, data []byte) error {
+							var dl domain.DeadLetter
+							assert.NoError(t, json.Unmarshal(data, &dl))
+							assert.Equal(t, int64(7), dl.Id)
+							return nil
+						}),
+				)
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pub := mock_nats_streaming.NewMockPublisher(ctrl)
+			store := mock_storage.NewMockDeadLetterStorage(ctrl)
+			tc.prepare(pub, store)
+
+			msg := mock_nats_streaming.NewMockMessage(ctrl)
+			msg.EXPECT().Subject().Return("order-notification").AnyTimes()
+			msg.EXPECT().Sequence().Return(uint64(42)).AnyTimes()
+			msg.EXPECT().Attempt().Return(3).AnyTimes()
+			msg.EXPECT().Data().Return([]byte("{}")).AnyTimes()
+
+			q := deadletter.New(logging.Discard(), pub, store, dlqChannel)
+
+			err := q.Put(context.Background(), msg, errors.New("invalid data"))
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_Replay(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		wantErr   error
+		prepare   func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage)
+	}{
+		{
+			test_name: "Success",
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				dl := &domain.DeadLetter{Id: 1, Channel: "order-notification", Data: []byte("{}")}
+				gomock.InOrder(
+					store.EXPECT().GetDeadLetter(gomock.Any(), int64(1)).Return(dl, nil),
+					pub.EXPECT().Publish("order-notification", []byte("{}")).Return(nil),
+					store.EXPECT().DeleteDeadLetter(gomock.Any(), int64(1)).Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Not found",
+			wantErr:   storage.ErrEntryDoesntExists,
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				store.EXPECT().GetDeadLetter(gomock.Any(), int64(1)).Return(nil, storage.ErrEntryDoesntExists)
+			},
+		},
+		{
+			test_name: "Publish failed keeps the dead letter",
+			wantErr:   errPublish,
+			prepare: func(pub *mock_nats_streaming.MockPublisher, store *mock_storage.MockDeadLetterStorage) {
+				dl := &domain.DeadLetter{Id: 1, Channel: "order-notification", Data: []byte("{}")}
+				gomock.InOrder(
+					store.EXPECT().GetDeadLetter(gomock.Any(), int64(1)).Return(dl, nil),
+					pub.EXPECT().Publish("order-notification", []byte("{}")).Return(errPublish),
+				)
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			pub := mock_nats_streaming.NewMockPublisher(ctrl)
+			store := mock_storage.NewMockDeadLetterStorage(ctrl)
+			tc.prepare(pub, store)
+
+			q := deadletter.New(logging.Discard(), pub, store, dlqChannel)
+
+			err := q.Replay(context.Background(), 1)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+)
+
+// DeadLetter is a message that could not be processed, together with the failure details
+type DeadLetter struct {
+	Id       int64     `json:"id"`
+	Channel  string    `json:"channel"`
+	Sequence uint64    `json:"sequence"`
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	Data     []byte    `json:"data"`
+	FailedAt time.Time `json:"failed_at"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+)
+
+// IdempotencyKey records a request made with an idempotency key, so that
+// retries of the request are recognized. OrderUid is empty while the
+// request is still being handled
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	OrderUid    string    `json:"order_uid,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
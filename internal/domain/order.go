@@ -5,55 +5,57 @@ import (
 )
 
 type Delivery struct {
-	Name    string `json:"name"`
-	Phone   string `json:"phone"`
-	Zip     string `json:"zip"`
-	City    string `json:"city"`
-	Address string `json:"address"`
-	Region  string `json:"region"`
-	Email   string `json:"email"`
+	Name    string `json:"name" validate:"required"`
+	Phone   string `json:"phone" validate:"required,e164"`
+	Zip     string `json:"zip" validate:"required"`
+	City    string `json:"city" validate:"required"`
+	Address string `json:"address" validate:"required"`
+	Region  string `json:"region" validate:"required"`
+	Email   string `json:"email" validate:"required,email"`
 }
 
+// Payment and Item numbers are stored in INTEGER columns, hence lte=2147483647
 type Payment struct {
-	Transaction  string `json:"transaction"`
+	Transaction  string `json:"transaction" validate:"required"`
 	RequestId    string `json:"request_id"`
-	Currency     string `json:"currency"`
-	Provider     string `json:"provider"`
-	Amount       int    `json:"amount"`
-	PaymentDt    int    `json:"payment_dt"`
-	Bank         string `json:"bank"`
-	DeliveryCost int    `json:"delivery_cost"`
-	GoodsTotal   int    `json:"goods_total"`
-	CustomFee    int    `json:"custom_fee"`
+	Currency     string `json:"currency" validate:"required,iso4217"`
+	Provider     string `json:"provider" validate:"required"`
+	Amount       int    `json:"amount" validate:"gte=0,lte=2147483647"`
+	PaymentDt    int    `json:"payment_dt" validate:"gt=0"`
+	Bank         string `json:"bank" validate:"required"`
+	DeliveryCost int    `json:"delivery_cost" validate:"gte=0,lte=2147483647"`
+	GoodsTotal   int    `json:"goods_total" validate:"gte=0,lte=2147483647"`
+	CustomFee    int    `json:"custom_fee" validate:"gte=0,lte=2147483647"`
 }
 
 type Item struct {
-	ChrtId      int    `json:"chrt_id"`
-	TrackNumber string `json:"track_number"`
-	Price       int    `json:"price"`
-	Rid         string `json:"rid"`
-	Name        string `json:"name"`
-	Sale        int    `json:"sale"`
-	Size        string `json:"size"`
-	TotalPrice  int    `json:"total_price"`
-	NmId        int    `json:"nm_id"`
-	Brand       string `json:"brand"`
-	Status      int    `json:"status"`
+	ChrtId      int    `json:"chrt_id" validate:"gt=0,lte=2147483647"`
+	TrackNumber string `json:"track_number" validate:"required"`
+	Price       int    `json:"price" validate:"gte=0,lte=2147483647"`
+	Rid         string `json:"rid" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	Sale        int    `json:"sale" validate:"gte=0,lte=100"`
+	Size        string `json:"size" validate:"required"`
+	TotalPrice  int    `json:"total_price" validate:"gte=0,lte=2147483647"`
+	NmId        int    `json:"nm_id" validate:"gt=0,lte=2147483647"`
+	Brand       string `json:"brand" validate:"required"`
+	Status      int    `json:"status" validate:"gte=0,lte=2147483647"`
 }
 
 type Order struct {
-	OrderUid          string    `json:"order_uid"`
-	TrackNumber       string    `json:"track_number"`
-	Entry             string    `json:"entry"`
-	Delivery          Delivery  `json:"delivery"`
-	Payment           Payment   `json:"payment"`
-	Items             []Item    `json:"items"`
-	Locale            string    `json:"locale"`
-	InternalSignature string    `json:"internal_signature"`
-	CustomerId        string    `json:"customer_id"`
-	DeliveryService   string    `json:"delivery_service"`
-	Shardkey          string    `json:"shardkey"`
-	SmId              int       `json:"sm_id"`
-	DateCreated       time.Time `json:"date_created"`
-	OofShard          string    `json:"oof_shard"`
+	OrderUid          string      `json:"order_uid" validate:"required,order_uid"`
+	TrackNumber       string      `json:"track_number" validate:"required"`
+	Entry             string      `json:"entry" validate:"required"`
+	Delivery          Delivery    `json:"delivery"`
+	Payment           Payment     `json:"payment"`
+	Items             []Item      `json:"items" validate:"required,min=1,dive"`
+	Locale            string      `json:"locale" validate:"required"`
+	InternalSignature string      `json:"internal_signature"`
+	CustomerId        string      `json:"customer_id" validate:"required"`
+	DeliveryService   string      `json:"delivery_service" validate:"required"`
+	Shardkey          string      `json:"shardkey" validate:"required"`
+	SmId              int         `json:"sm_id" validate:"gte=0,lte=2147483647"`
+	DateCreated       time.Time   `json:"date_created" validate:"required"`
+	OofShard          string      `json:"oof_shard" validate:"required"`
+	Status            OrderStatus `json:"status,omitempty" validate:"omitempty,order_status"`
 }
@@ -0,0 +1,27 @@
+package domain
+
+import "unsafe"
+
+// SizeEstimate approximates the memory held by the order: the structs
+// themselves plus the string contents, ignoring allocator overhead
+func (o *Order) SizeEstimate() int64 {
+	size := int64(unsafe.Sizeof(*o)) +
+		int64(len(o.OrderUid)+len(o.TrackNumber)+len(o.Entry)+len(o.Locale)+
+			len(o.InternalSignature)+len(o.CustomerId)+len(o.DeliveryService)+
+			len(o.Shardkey)+len(o.OofShard)+len(o.Status))
+
+	d := o.Delivery
+	size += int64(len(d.Name) + len(d.Phone) + len(d.Zip) + len(d.City) +
+		len(d.Address) + len(d.Region) + len(d.Email))
+
+	p := o.Payment
+	size += int64(len(p.Transaction) + len(p.RequestId) + len(p.Currency) +
+		len(p.Provider) + len(p.Bank))
+
+	size += int64(cap(o.Items)) * int64(unsafe.Sizeof(Item{}))
+	for _, i := range o.Items {
+		size += int64(len(i.TrackNumber) + len(i.Rid) + len(i.Name) + len(i.Size) + len(i.Brand))
+	}
+
+	return size
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SizeEstimate(t *testing.T) {
+	small := validOrder()
+	large := validOrder()
+
+	for i := 0; i < 100; i++ {
+		large.Items = append(large.Items, large.Items[0])
+	}
+
+	assert.Greater(t, small.SizeEstimate(), int64(0))
+	assert.Greater(t, large.SizeEstimate(), small.SizeEstimate()+100*int64(len(large.Items[0].Name)))
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StatusTransitions(t *testing.T) {
+	test_cases := []struct {
+		from OrderStatus
+		to   OrderStatus
+		want bool
+	}{
+		{StatusCreated, StatusPaid, true},
+		{StatusCreated, StatusCancelled, true},
+		{StatusCreated, StatusShipped, false},
+		{StatusPaid, StatusShipped, true},
+		{StatusPaid, StatusCreated, false},
+		{StatusShipped, StatusDelivered, true},
+		{StatusShipped, StatusCancelled, false},
+		{StatusDelivered, StatusReturned, true},
+		{StatusCancelled, StatusPaid, false},
+		{StatusReturned, StatusDelivered, false},
+		{OrderStatus("lost"), StatusPaid, false},
+	}
+
+	for _, tc := range test_cases {
+		assert.Equal(t, tc.want, tc.from.CanTransitionTo(tc.to), "%s -> %s", tc.from, tc.to)
+	}
+
+	assert.True(t, StatusReturned.Valid())
+	assert.False(t, OrderStatus("lost").Valid())
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator"
+)
+
+// orderUid matches the ids accepted by the /orders/{order_uid} route
+var orderUid = regexp.MustCompile(`^[a-z0-9]{19}$`)
+
+// iso4217 lists the active ISO 4217 currency codes
+var iso4217 = map[string]struct{}{}
+
+func init() {
+	const codes = "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL " +
+		"BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP " +
+		"ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR " +
+		"IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL " +
+		"LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR " +
+		"NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD " +
+		"SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX " +
+		"USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL"
+
+	for _, code := range strings.Fields(codes) {
+		iso4217[code] = struct{}{}
+	}
+}
+
+// FieldError describes a single failed rule, Field is the json path of the value
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func (e FieldError) Error() string {
+	if e.Param != "" {
+		return fmt.Sprintf("%s: failed %s=%s", e.Field, e.Rule, e.Param)
+	}
+	return fmt.Sprintf("%s: failed %s", e.Field, e.Rule)
+}
+
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+func orderValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+
+		// report fields by their json names
+		validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		_ = validate.RegisterValidation("order_uid", func(fl validator.FieldLevel) bool {
+			return orderUid.MatchString(fl.Field().String())
+		})
+
+		_ = validate.RegisterValidation("iso4217", func(fl validator.FieldLevel) bool {
+			_, ok := iso4217[fl.Field().String()]
+			return ok
+		})
+
+		_ = validate.RegisterValidation("order_status", func(fl validator.FieldLevel) bool {
+			return OrderStatus(fl.Field().String()).Valid()
+		})
+
+		validate.RegisterStructValidation(validateOrderTotals, Order{})
+	})
+
+	return validate
+}
+
+// validateOrderTotals checks that the payment matches the items
+func validateOrderTotals(sl validator.StructLevel) {
+	order := sl.Current().Interface().(Order)
+
+	goods := 0
+	for i, item := range order.Items {
+		goods += item.TotalPrice
+
+		if item.TrackNumber != order.TrackNumber {
+			sl.ReportError(item.TrackNumber, fmt.Sprintf("items[%d].track_number", i), "TrackNumber", "eqfield", "track_number")
+		}
+	}
+
+	p := order.Payment
+
+	if p.GoodsTotal != goods {
+		sl.ReportError(p.GoodsTotal, "payment.goods_total", "GoodsTotal", "eq", fmt.Sprint(goods))
+	}
+
+	if total := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != total {
+		sl.ReportError(p.Amount, "payment.amount", "Amount", "eq", fmt.Sprint(total))
+	}
+}
+
+// Validate checks the order against its business rules,
+// the returned error is ValidationErrors if any rule fails
+func (o Order) Validate() error {
+	err := orderValidator().Struct(o)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	res := make(ValidationErrors, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		res = append(res, FieldError{
+			Field: fieldPath(fe),
+			Rule:  fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+
+	return res
+}
+
+// fieldPath strips the root struct name from the namespace, "Order.delivery.email" -> "delivery.email".
+// Struct level errors already carry the full path as the field name
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		ns = ns[i+1:]
+	}
+	return ns
+}
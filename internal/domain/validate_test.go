@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validOrder() Order {
+	return Order{
+		OrderUid:    "b563feb7b2b84b64c8w",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: Payment{
+			Transaction:  "b563feb7b2b84b64c8w",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []Item{
+			{
+				ChrtId:      9934930,
+				TrackNumber: "WBILMTESTTRACK",
+				Price:       453,
+				Rid:         "ab4219087a764ae0btest",
+				Name:        "Mascaras",
+				Sale:        30,
+				Size:        "0",
+				TotalPrice:  317,
+				NmId:        2389212,
+				Brand:       "Vivienne Sabo",
+				Status:      202,
+			},
+		},
+		Locale:          "en",
+		CustomerId:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmId:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+}
+
+func Test_ValidateOrder(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		modify    func(o *Order)
+		want      ValidationErrors
+	}{
+		{
+			test_name: "Valid order",
+			modify:    func(o *Order) {},
+		},
+		{
+			test_name: "Uid format",
+			modify:    func(o *Order) { o.OrderUid = "B563FEB7B2B84B64C8W" },
+			want:      ValidationErrors{{Field: "order_uid", Rule: "order_uid"}},
+		},
+		{
+			test_name: "Contacts format",
+			modify: func(o *Order) {
+				o.Delivery.Email = "test"
+				o.Delivery.Phone = "8 (800) 000"
+			},
+			want: ValidationErrors{
+				{Field: "delivery.phone", Rule: "e164"},
+				{Field: "delivery.email", Rule: "email"},
+			},
+		},
+		{
+			test_name: "Unknown currency",
+			modify:    func(o *Order) { o.Payment.Currency = "usd" },
+			want:      ValidationErrors{{Field: "payment.currency", Rule: "iso4217"}},
+		},
+		{
+			test_name: "Negative amounts",
+			modify: func(o *Order) {
+				o.Items[0].Price = -1
+				o.Payment.CustomFee = -1
+				o.Payment.Amount = 1816
+			},
+			want: ValidationErrors{
+				{Field: "payment.custom_fee", Rule: "gte", Param: "0"},
+				{Field: "items[0].price", Rule: "gte", Param: "0"},
+			},
+		},
+		{
+			test_name: "Amounts out of INTEGER range",
+			modify: func(o *Order) {
+				o.Items[0].TotalPrice = 1 << 40
+				o.Payment.GoodsTotal = 1 << 40
+				o.Payment.Amount = 1<<40 + 1500
+			},
+			want: ValidationErrors{
+				{Field: "payment.amount", Rule: "lte", Param: "2147483647"},
+				{Field: "payment.goods_total", Rule: "lte", Param: "2147483647"},
+				{Field: "items[0].total_price", Rule: "lte", Param: "2147483647"},
+			},
+		},
+		{
+			test_name: "No items",
+			modify: func(o *Order) {
+				o.Items = nil
+				o.Payment.GoodsTotal = 0
+				o.Payment.Amount = 1500
+			},
+			want: ValidationErrors{{Field: "items", Rule: "required"}},
+		},
+		{
+			test_name: "Goods total mismatch",
+			modify:    func(o *Order) { o.Items[0].TotalPrice = 300 },
+			want:      ValidationErrors{{Field: "payment.goods_total", Rule: "eq", Param: "300"}},
+		},
+		{
+			test_name: "Amount mismatch",
+			modify:    func(o *Order) { o.Payment.Amount = 317 },
+			want:      ValidationErrors{{Field: "payment.amount", Rule: "eq", Param: "1817"}},
+		},
+		{
+			test_name: "Item of another order",
+			modify:    func(o *Order) { o.Items[0].TrackNumber = "WBILMOTHERTRACK" },
+			want:      ValidationErrors{{Field: "items[0].track_number", Rule: "eqfield", Param: "track_number"}},
+		},
+		{
+			test_name: "Unknown status",
+			modify:    func(o *Order) { o.Status = "lost" },
+			want:      ValidationErrors{{Field: "status", Rule: "order_status"}},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			order := validOrder()
+			tc.modify(&order)
+
+			err := order.Validate()
+			if tc.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds a single dependency check, so a hung
+// dependency doesn't hang the probe
+const checkTimeout = 2 * time.Second
+
+type State string
+
+const (
+	StateStarting State = "starting"
+	// StateWarming is the service up but still filling its cache
+	StateWarming  State = "warming"
+	StateReady    State = "ready"
+	StateStopping State = "stopping"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check reports a dependency problem by returning an error
+type Check func(ctx context.Context) error
+
+type Report struct {
+	Status string            `json:"status"`
+	State  State             `json:"state,omitempty"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health tracks the dependencies of the service and its lifecycle state.
+// The service is healthy while all dependencies are, and ready
+// once it is healthy and has finished starting
+type Health struct {
+	mu     sync.RWMutex
+	state  State
+	checks []namedCheck
+}
+
+func New() *Health {
+	return &Health{
+		state: StateStarting,
+	}
+}
+
+func (h *Health) AddCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+func (h *Health) SetState(state State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.state = state
+}
+
+// SwapState changes the state only if it is still old, so a late
+// start-up step doesn't mark a stopping service ready
+func (h *Health) SwapState(old, new State) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.state != old {
+		return false
+	}
+
+	h.state = new
+	return true
+}
+
+func (h *Health) State() State {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.state
+}
+
+// Health runs all dependency checks concurrently
+func (h *Health) Health(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]error, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = c.check(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]string, len(checks)),
+	}
+
+	for i, c := range checks {
+		if results[i] != nil {
+			report.Status = StatusUnavailable
+			report.Checks[c.name] = results[i].Error()
+			continue
+		}
+		report.Checks[c.name] = StatusOK
+	}
+
+	return report
+}
+
+// Readiness is the health report, unavailable unless the service is in the ready state
+func (h *Health) Readiness(ctx context.Context) Report {
+	report := h.Health(ctx)
+
+	report.State = h.State()
+	if report.State != StateReady {
+		report.Status = StatusUnavailable
+	}
+
+	return report
+}
@@ -0,0 +1,86 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"test-task/order-service/internal/health"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Health(t *testing.T) {
+	test_cases := []struct {
+		test_name   string
+		state       health.State
+		checkErr    error
+		wantHealthy bool
+		wantReady   bool
+	}{
+		{
+			test_name:   "Ready",
+			state:       health.StateReady,
+			wantHealthy: true,
+			wantReady:   true,
+		},
+		{
+			test_name:   "Starting",
+			state:       health.StateStarting,
+			wantHealthy: true,
+		},
+		{
+			test_name:   "Warming",
+			state:       health.StateWarming,
+			wantHealthy: true,
+		},
+		{
+			test_name:   "Stopping",
+			state:       health.StateStopping,
+			wantHealthy: true,
+		},
+		{
+			test_name: "Dependency down",
+			state:     health.StateReady,
+			checkErr:  errors.New("connection refused"),
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			h := health.New()
+			h.AddCheck("postgres", func(ctx context.Context) error { return nil })
+			h.AddCheck("nats", func(ctx context.Context) error { return tc.checkErr })
+			h.SetState(tc.state)
+
+			report := h.Health(context.Background())
+			assert.Equal(t, tc.wantHealthy, report.OK())
+			assert.Equal(t, health.StatusOK, report.Checks["postgres"])
+
+			if tc.checkErr != nil {
+				assert.Equal(t, tc.checkErr.Error(), report.Checks["nats"])
+			}
+
+			ready := h.Readiness(context.Background())
+			assert.Equal(t, tc.wantReady, ready.OK())
+			assert.Equal(t, tc.state, ready.State)
+		})
+	}
+}
+
+func Test_SwapState(t *testing.T) {
+	h := health.New()
+
+	h.SetState(health.StateStopping)
+
+	assert.False(t, h.SwapState(health.StateStarting, health.StateReady))
+	assert.Equal(t, health.StateStopping, h.State())
+
+	h.SetState(health.StateStarting)
+
+	assert.True(t, h.SwapState(health.StateStarting, health.StateReady))
+	assert.Equal(t, health.StateReady, h.State())
+}
@@ -0,0 +1,58 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+)
+
+type DeadLetterLister interface {
+	List(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error)
+}
+
+func New(log *slog.Logger, lister DeadLetterLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deadletter.list.New"
+
+		log := log.With(slog.String("op", op))
+
+		q := r.URL.Query()
+
+		var (
+			afterId int64
+			limit   = storage.DefaultListLimit
+			err     error
+		)
+
+		if v := q.Get("after"); v != "" {
+			if afterId, err = strconv.ParseInt(v, 10, 64); err != nil || afterId < 0 {
+				log.WarnContext(r.Context(), "invalid after", slog.String("after", v))
+				http_server.RespondWithError(errors.New("invalid after"), w, r, "invalid after", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if v := q.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > storage.MaxListLimit {
+				log.WarnContext(r.Context(), "invalid limit", slog.String("limit", v))
+				http_server.RespondWithError(errors.New("invalid limit"), w, r, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		res, err := lister.List(r.Context(), afterId, limit)
+		if err != nil {
+			log.ErrorContext(r.Context(), "failed to list dead letters", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		http_server.RespondOK(res, w, r)
+	}
+}
@@ -0,0 +1,108 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/http-server/handlers/deadletter/list"
+	mock_list "test-task/order-service/internal/http-server/handlers/deadletter/list/mocks"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ListHandler(t *testing.T) {
+	test_cases := []struct {
+		test_name  string
+		query      string
+		want       []domain.DeadLetter
+		statusCode int
+		respErr    string
+		prepare    func(m *mock_list.MockDeadLetterLister)
+	}{
+		{
+			test_name:  "First page",
+			want:       []domain.DeadLetter{{Id: 1, Channel: "order-notification"}, {Id: 2, Channel: "order-notification"}},
+			statusCode: 200,
+			prepare: func(m *mock_list.MockDeadLetterLister) {
+				m.EXPECT().List(gomock.Any(), int64(0), storage.DefaultListLimit).
+					Return([]domain.DeadLetter{{Id: 1, Channel: "order-notification"}, {Id: 2, Channel: "order-notification"}}, nil)
+			},
+		},
+		{
+			test_name:  "Next page",
+			query:      "?after=2&limit=1",
+			want:       []domain.DeadLetter{{Id: 3, Channel: "order-status"}},
+			statusCode: 200,
+			prepare: func(m *mock_list.MockDeadLetterLister) {
+				m.EXPECT().List(gomock.Any(), int64(2), 1).Return([]domain.DeadLetter{{Id: 3, Channel: "order-status"}}, nil)
+			},
+		},
+		{
+			test_name:  "Invalid after",
+			query:      "?after=abc",
+			statusCode: 400,
+			respErr:    "invalid after",
+		},
+		{
+			test_name:  "Negative after",
+			query:      "?after=-1",
+			statusCode: 400,
+			respErr:    "invalid after",
+		},
+		{
+			test_name:  "Limit over maximum",
+			query:      "?limit=1001",
+			statusCode: 400,
+			respErr:    "invalid limit",
+		},
+		{
+			test_name:  "Internal Error",
+			statusCode: 500,
+			respErr:    "internal error",
+			prepare: func(m *mock_list.MockDeadLetterLister) {
+				m.EXPECT().List(gomock.Any(), int64(0), storage.DefaultListLimit).Return(nil, errors.New("connection refused"))
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lister := mock_list.NewMockDeadLetterLister(ctrl)
+			if tc.prepare != nil {
+				tc.prepare(lister)
+			}
+
+			req := httptest.NewRequest("GET", "/dead-letters"+tc.query, nil)
+
+			rec := httptest.NewRecorder()
+
+			list.New(logging.Discard(), lister).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+
+			if tc.respErr != "" {
+				var resp http_server.Response
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tc.respErr, resp.Error)
+				return
+			}
+
+			var got []domain.DeadLetter
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/http-server/handlers/deadletter/list/list.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/deadletter/list/list.go -destination=internal/http-server/handlers/deadletter/list/mocks/dead_letter_lister.go
+//
+
+// Package mock_list is a generated GoMock package.
+package mock_list
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "test-task/order-service/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterLister is a mock of DeadLetterLister interface.
+type MockDeadLetterLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterListerMockRecorder
+}
+
+// MockDeadLetterListerMockRecorder is the mock recorder for MockDeadLetterLister.
+type MockDeadLetterListerMockRecorder struct {
+	mock *MockDeadLetterLister
+}
+
+// NewMockDeadLetterLister creates a new mock instance.
+func NewMockDeadLetterLister(ctrl *gomock.Controller) *MockDeadLetterLister {
+	mock := &MockDeadLetterLister{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterLister) EXPECT() *MockDeadLetterListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockDeadLetterLister) List(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, afterId, limit)
+	ret0, _ := ret[0].([]domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDeadLetterListerMockRecorder) List(ctx, afterId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeadLetterLister)(nil).List), ctx, afterId, limit)
+}
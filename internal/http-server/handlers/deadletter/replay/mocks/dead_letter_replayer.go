@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/http-server/handlers/deadletter/replay/replay.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/deadletter/replay/replay.go -destination=internal/http-server/handlers/deadletter/replay/mocks/dead_letter_replayer.go
+//
+
+// Package mock_replay is a generated GoMock package.
+package mock_replay
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterReplayer is a mock of DeadLetterReplayer interface.
+type MockDeadLetterReplayer struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterReplayerMockRecorder
+}
+
+// MockDeadLetterReplayerMockRecorder is the mock recorder for MockDeadLetterReplayer.
+type MockDeadLetterReplayerMockRecorder struct {
+	mock *MockDeadLetterReplayer
+}
+
+// NewMockDeadLetterReplayer creates a new mock instance.
+func NewMockDeadLetterReplayer(ctrl *gomock.Controller) *MockDeadLetterReplayer {
+	mock := &MockDeadLetterReplayer{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterReplayerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterReplayer) EXPECT() *MockDeadLetterReplayerMockRecorder {
+	return m.recorder
+}
+
+// Replay mocks base method.
+func (m *MockDeadLetterReplayer) Replay(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockDeadLetterReplayerMockRecorder) Replay(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockDeadLetterReplayer)(nil).Replay), ctx, id)
+}
@@ -0,0 +1,51 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+
+	"github.com/gorilla/mux"
+)
+
+type DeadLetterReplayer interface {
+	Replay(ctx context.Context, id int64) error
+}
+
+func New(log *slog.Logger, replayer DeadLetterReplayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deadletter.replay.New"
+
+		log := log.With(slog.String("op", op))
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			log.WarnContext(r.Context(), "id is incorrect")
+			http_server.RespondWithError(err, w, r, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		err = replayer.Replay(r.Context(), id)
+
+		if errors.Is(err, storage.ErrEntryDoesntExists) {
+			log.InfoContext(r.Context(), "dead letter not found", slog.Int64("id", id))
+			http_server.RespondWithError(err, w, r, "not found", http.StatusNotFound)
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(r.Context(), "failed to replay dead letter", slog.Int64("id", id), logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		log.InfoContext(r.Context(), "dead letter replayed", slog.Int64("id", id))
+
+		http_server.RespondOK(http_server.Response{Status: http_server.StatusOK}, w, r)
+	}
+}
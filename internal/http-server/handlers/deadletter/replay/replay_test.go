@@ -0,0 +1,94 @@
+package replay_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/http-server/handlers/deadletter/replay"
+	mock_replay "test-task/order-service/internal/http-server/handlers/deadletter/replay/mocks"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ReplayHandler(t *testing.T) {
+	test_cases := []struct {
+		test_name  string
+		id         string
+		statusCode int
+		respErr    string
+		prepare    func(m *mock_replay.MockDeadLetterReplayer)
+	}{
+		{
+			test_name:  "Replayed",
+			id:         "42",
+			statusCode: 200,
+			prepare: func(m *mock_replay.MockDeadLetterReplayer) {
+				m.EXPECT().Replay(gomock.Any(), int64(42)).Return(nil)
+			},
+		},
+		{
+			test_name:  "A dead letter with a non-existent ID",
+			id:         "43",
+			statusCode: 404,
+			respErr:    "not found",
+			prepare: func(m *mock_replay.MockDeadLetterReplayer) {
+				m.EXPECT().Replay(gomock.Any(), int64(43)).Return(fmt.Errorf("deadletter.Replay: %w", storage.ErrEntryDoesntExists))
+			},
+		},
+		{
+			test_name:  "ID out of range",
+			id:         "99999999999999999999",
+			statusCode: 400,
+			respErr:    "invalid request",
+		},
+		{
+			test_name:  "Publish failure",
+			id:         "42",
+			statusCode: 500,
+			respErr:    "internal error",
+			prepare: func(m *mock_replay.MockDeadLetterReplayer) {
+				m.EXPECT().Replay(gomock.Any(), int64(42)).Return(errors.New("deadletter.Replay: nats: connection closed"))
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			replayer := mock_replay.NewMockDeadLetterReplayer(ctrl)
+			if tc.prepare != nil {
+				tc.prepare(replayer)
+			}
+
+			router := mux.NewRouter()
+			router.HandleFunc("/dead-letters/{id:[0-9]+}/replay", replay.New(logging.Discard(), replayer)).Methods("POST")
+
+			req := httptest.NewRequest("POST", fmt.Sprintf("/dead-letters/%s/replay", tc.id), nil)
+
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+
+			var resp http_server.Response
+
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+			assert.Equal(t, tc.respErr, resp.Error)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"test-task/order-service/internal/health"
+	http_server "test-task/order-service/internal/http-server"
+)
+
+type Checker interface {
+	Health(ctx context.Context) health.Report
+	Readiness(ctx context.Context) health.Report
+}
+
+// NewHealthz answers 503 while any dependency is unavailable
+func NewHealthz(log *slog.Logger, checker Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.NewHealthz"
+
+		respond(log.With(slog.String("op", op)), checker.Health(r.Context()), w, r)
+	}
+}
+
+// NewReadyz answers 503 while the service is starting, stopping
+// or any dependency is unavailable
+func NewReadyz(log *slog.Logger, checker Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.NewReadyz"
+
+		respond(log.With(slog.String("op", op)), checker.Readiness(r.Context()), w, r)
+	}
+}
+
+func respond(log *slog.Logger, report health.Report, w http.ResponseWriter, r *http.Request) {
+	if !report.OK() {
+		log.WarnContext(r.Context(), "service unavailable",
+			slog.String("state", string(report.State)), slog.Any("checks", report.Checks))
+		http_server.Respond(report, http.StatusServiceUnavailable, w, r)
+		return
+	}
+
+	http_server.RespondOK(report, w, r)
+}
@@ -0,0 +1,115 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"test-task/order-service/internal/health"
+	handler "test-task/order-service/internal/http-server/handlers/health"
+	mock_health "test-task/order-service/internal/http-server/handlers/health/mocks"
+	"test-task/order-service/internal/logging"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_HealthHandlers(t *testing.T) {
+	healthy := health.Report{
+		Status: health.StatusOK,
+		Checks: map[string]string{"postgres": health.StatusOK},
+	}
+
+	test_cases := []struct {
+		test_name  string
+		path       string
+		want       health.Report
+		statusCode int
+		prepare    func(m *mock_health.MockChecker)
+	}{
+		{
+			test_name:  "Healthy",
+			path:       "/healthz",
+			want:       healthy,
+			statusCode: http.StatusOK,
+			prepare: func(m *mock_health.MockChecker) {
+				m.EXPECT().Health(gomock.Any()).Return(healthy)
+			},
+		},
+		{
+			test_name: "Dependency down",
+			path:      "/healthz",
+			want: health.Report{
+				Status: health.StatusUnavailable,
+				Checks: map[string]string{"postgres": "connection refused"},
+			},
+			statusCode: http.StatusServiceUnavailable,
+			prepare: func(m *mock_health.MockChecker) {
+				m.EXPECT().Health(gomock.Any()).Return(health.Report{
+					Status: health.StatusUnavailable,
+					Checks: map[string]string{"postgres": "connection refused"},
+				})
+			},
+		},
+		{
+			test_name: "Ready",
+			path:      "/readyz",
+			want: health.Report{
+				Status: health.StatusOK,
+				State:  health.StateReady,
+			},
+			statusCode: http.StatusOK,
+			prepare: func(m *mock_health.MockChecker) {
+				m.EXPECT().Readiness(gomock.Any()).Return(health.Report{
+					Status: health.StatusOK,
+					State:  health.StateReady,
+				})
+			},
+		},
+		{
+			test_name: "Starting",
+			path:      "/readyz",
+			want: health.Report{
+				Status: health.StatusUnavailable,
+				State:  health.StateStarting,
+			},
+			statusCode: http.StatusServiceUnavailable,
+			prepare: func(m *mock_health.MockChecker) {
+				m.EXPECT().Readiness(gomock.Any()).Return(health.Report{
+					Status: health.StatusUnavailable,
+					State:  health.StateStarting,
+				})
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			checker := mock_health.NewMockChecker(ctrl)
+			tc.prepare(checker)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/healthz", handler.NewHealthz(logging.Discard(), checker)).Methods("GET")
+			router.HandleFunc("/readyz", handler.NewReadyz(logging.Discard(), checker)).Methods("GET")
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+
+			var got health.Report
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package create
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/service"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader marks retries of the same request, an order
+	// created with a key is answered with 201 again on a retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on the answers to the retries
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	maxBodyBytes = 1 << 20
+)
+
+// invalidOrderResponse lists the rules the order failed
+type invalidOrderResponse struct {
+	http_server.Response
+	Fields domain.ValidationErrors `json:"fields,omitempty"`
+}
+
+type OrderCreator interface {
+	Ingest(ctx context.Context, data []byte) (*domain.Order, error)
+}
+
+type OrderGetter interface {
+	Get(ctx context.Context, orderId string) (*domain.Order, error)
+}
+
+type IdempotencyKeys interface {
+	ClaimIdempotencyKey(ctx context.Context, key string, requestHash string, staleAfter time.Duration) (*domain.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, orderId string) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+type KeyPurger interface {
+	PurgeIdempotencyKeys(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// New creates orders the same way they are ingested from the channel.
+// It answers 201 with the order, 409 when the order exists already or
+// a request with the same idempotency key is in progress, and 422 when
+// the order is invalid, listing the failed fields, or the idempotency key
+// was used for another request.
+// A request in progress for longer than claimTimeout is taken for abandoned
+func New(log *slog.Logger, creator OrderCreator, orders OrderGetter, keys IdempotencyKeys, claimTimeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.order.create.New"
+
+		log := log.With(slog.String("op", op))
+
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if len(key) > maxKeyLength {
+			log.WarnContext(r.Context(), "idempotency key is too long")
+			http_server.RespondWithError(errors.New("idempotency key is too long"), w, r, "invalid idempotency key", http.StatusBadRequest)
+			return
+		}
+
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			log.WarnContext(r.Context(), "failed reading body", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if key != "" {
+			log = log.With(slog.String("idempotency_key", key))
+
+			sum := sha256.Sum256(data)
+			hash := hex.EncodeToString(sum[:])
+
+			recorded, err := keys.ClaimIdempotencyKey(r.Context(), key, hash, claimTimeout)
+
+			switch {
+			case errors.Is(err, storage.ErrEntryAlreadyExists):
+				replay(log, orders, recorded, hash, w, r)
+				return
+			case err != nil:
+				log.ErrorContext(r.Context(), "failed claiming idempotency key", logging.Err(err))
+				http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		order, err := creator.Ingest(r.Context(), data)
+
+		if err != nil && key != "" {
+			// the key is released even if the client is gone,
+			// otherwise its retries would be stuck in progress
+			if err := keys.ReleaseIdempotencyKey(context.WithoutCancel(r.Context()), key); err != nil {
+				log.ErrorContext(r.Context(), "failed releasing idempotency key", logging.Err(err))
+			}
+		}
+
+		switch {
+		case errors.Is(err, service.ErrInvalidOrder):
+			log.InfoContext(r.Context(), "invalid order", logging.Err(err))
+
+			// the fields are missing when the body isn't an order at all
+			resp := invalidOrderResponse{Response: http_server.Error("invalid order")}
+			errors.As(err, &resp.Fields)
+
+			http_server.Respond(resp, http.StatusUnprocessableEntity, w, r)
+			return
+		case errors.Is(err, storage.ErrEntryConflict):
+			metrics.OrderConflicts.Inc()
+			log.WarnContext(r.Context(), "order exists with different data", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "order exists with different data", http.StatusConflict)
+			return
+		case errors.Is(err, storage.ErrEntryAlreadyExists):
+			log.InfoContext(r.Context(), "order exists already", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "order already exists", http.StatusConflict)
+			return
+		case err != nil:
+			log.ErrorContext(r.Context(), "failed to create order", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if key != "" {
+			// the order is stored, failing here only leaves a retry answered with 409
+			if err := keys.CompleteIdempotencyKey(context.WithoutCancel(r.Context()), key, order.OrderUid); err != nil {
+				log.ErrorContext(r.Context(), "failed completing idempotency key", logging.Err(err))
+			}
+		}
+
+		log.InfoContext(r.Context(), "order created", slog.String("order_uid", order.OrderUid))
+
+		http_server.Respond(order, http.StatusCreated, w, r)
+	}
+}
+
+// replay answers a retry of a request with an idempotency key
+func replay(log *slog.Logger, orders OrderGetter, recorded *domain.IdempotencyKey, hash string, w http.ResponseWriter, r *http.Request) {
+	if recorded.RequestHash != hash {
+		log.WarnContext(r.Context(), "idempotency key reused for another request")
+		http_server.RespondWithError(errors.New("request differs"), w, r, "idempotency key used for another request", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if recorded.OrderUid == "" {
+		log.InfoContext(r.Context(), "request with idempotency key in progress")
+		http_server.RespondWithError(errors.New("request in progress"), w, r, "request in progress", http.StatusConflict)
+		return
+	}
+
+	// answering with the stored order, as the first request was answered
+	order, err := orders.Get(r.Context(), recorded.OrderUid)
+	if err != nil {
+		log.ErrorContext(r.Context(), "failed to get replayed order", slog.String("order_uid", recorded.OrderUid), logging.Err(err))
+		http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	log.InfoContext(r.Context(), "request replayed", slog.String("order_uid", recorded.OrderUid))
+
+	w.Header().Set(ReplayedHeader, "true")
+	http_server.Respond(order, http.StatusCreated, w, r)
+}
+
+// RunKeyPurge deletes the idempotency keys older than ttl every interval
+// until ctx is done, a retry coming later is handled as a new request
+func RunKeyPurge(ctx context.Context, log *slog.Logger, purger KeyPurger, ttl, interval time.Duration) {
+	const op = "handlers.order.create.RunKeyPurge"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := purger.PurgeIdempotencyKeys(ctx, ttl)
+			if err != nil {
+				log.Error("failed purging idempotency keys", logging.Err(err))
+				continue
+			}
+			log.Debug("idempotency keys purged", slog.Int64("count", purged))
+		}
+	}
+}
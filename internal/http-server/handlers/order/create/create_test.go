@@ -0,0 +1,243 @@
+package create_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/http-server/handlers/order/create"
+	mock_create "test-task/order-service/internal/http-server/handlers/order/create/mocks"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/service"
+	"test-task/order-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_CreateHandler(t *testing.T) {
+	type fields struct {
+		creator *mock_create.MockOrderCreator
+		orders  *mock_create.MockOrderGetter
+		keys    *mock_create.MockIdempotencyKeys
+	}
+
+	const claimTimeout = time.Minute
+
+	body := []byte(`{"order_uid":"b563feb7b2b84b64c8w"}`)
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+
+	created := &domain.Order{OrderUid: "b563feb7b2b84b64c8w", Status: domain.StatusCreated}
+
+	test_cases := []struct {
+		test_name  string
+		key        string
+		statusCode int
+		respErr    string
+		respFields domain.ValidationErrors
+		replayed   bool
+		prepare    func(f *fields)
+	}{
+		{
+			test_name:  "Created",
+			statusCode: 201,
+			prepare: func(f *fields) {
+				f.creator.EXPECT().Ingest(gomock.Any(), body).Return(created, nil)
+			},
+		},
+		{
+			test_name:  "Created with idempotency key",
+			key:        "7b2b84b6",
+			statusCode: 201,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(nil, nil),
+					f.creator.EXPECT().Ingest(gomock.Any(), body).Return(created, nil),
+					f.keys.EXPECT().CompleteIdempotencyKey(gomock.Any(), "7b2b84b6", "b563feb7b2b84b64c8w").Return(nil),
+				)
+			},
+		},
+		{
+			test_name:  "Retry with idempotency key",
+			key:        "7b2b84b6",
+			statusCode: 201,
+			replayed:   true,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(&domain.IdempotencyKey{
+						Key:         "7b2b84b6",
+						RequestHash: hash,
+						OrderUid:    "b563feb7b2b84b64c8w",
+					}, storage.ErrEntryAlreadyExists),
+					f.orders.EXPECT().Get(gomock.Any(), "b563feb7b2b84b64c8w").Return(created, nil),
+				)
+			},
+		},
+		{
+			test_name:  "Retry while in progress",
+			key:        "7b2b84b6",
+			statusCode: 409,
+			respErr:    "request in progress",
+			prepare: func(f *fields) {
+				f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(&domain.IdempotencyKey{
+					Key:         "7b2b84b6",
+					RequestHash: hash,
+				}, storage.ErrEntryAlreadyExists)
+			},
+		},
+		{
+			test_name:  "Idempotency key used for another request",
+			key:        "7b2b84b6",
+			statusCode: 422,
+			respErr:    "idempotency key used for another request",
+			prepare: func(f *fields) {
+				f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(&domain.IdempotencyKey{
+					Key:         "7b2b84b6",
+					RequestHash: strings.Repeat("0", 64),
+					OrderUid:    "9650f7fa5b404c2f996",
+				}, storage.ErrEntryAlreadyExists)
+			},
+		},
+		{
+			test_name:  "Idempotency key is too long",
+			key:        strings.Repeat("k", 256),
+			statusCode: 400,
+			respErr:    "invalid idempotency key",
+		},
+		{
+			test_name:  "Invalid order releases the key",
+			key:        "7b2b84b6",
+			statusCode: 422,
+			respErr:    "invalid order",
+			respFields: domain.ValidationErrors{
+				{Field: "payment.amount", Rule: "lte", Param: "2147483647"},
+				{Field: "payment.currency", Rule: "iso4217"},
+			},
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(nil, nil),
+					f.creator.EXPECT().Ingest(gomock.Any(), body).Return(nil, fmt.Errorf("invalid data: %w: %w", service.ErrInvalidOrder, domain.ValidationErrors{
+						{Field: "payment.amount", Rule: "lte", Param: "2147483647"},
+						{Field: "payment.currency", Rule: "iso4217"},
+					})),
+					f.keys.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "7b2b84b6").Return(nil),
+				)
+			},
+		},
+		{
+			test_name:  "Body is not an order",
+			statusCode: 422,
+			respErr:    "invalid order",
+			prepare: func(f *fields) {
+				f.creator.EXPECT().Ingest(gomock.Any(), body).Return(nil, fmt.Errorf("failed unmarshalling data: %w: %w", service.ErrInvalidOrder, errors.New("unexpected EOF")))
+			},
+		},
+		{
+			test_name:  "Order exists already",
+			statusCode: 409,
+			respErr:    "order already exists",
+			prepare: func(f *fields) {
+				f.creator.EXPECT().Ingest(gomock.Any(), body).Return(nil, storage.ErrEntryAlreadyExists)
+			},
+		},
+		{
+			test_name:  "Order exists with different data",
+			statusCode: 409,
+			respErr:    "order exists with different data",
+			prepare: func(f *fields) {
+				f.creator.EXPECT().Ingest(gomock.Any(), body).Return(nil, storage.ErrEntryConflict)
+			},
+		},
+		{
+			test_name:  "Internal Error",
+			key:        "7b2b84b6",
+			statusCode: 500,
+			respErr:    "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.keys.EXPECT().ClaimIdempotencyKey(gomock.Any(), "7b2b84b6", hash, claimTimeout).Return(nil, nil),
+					f.creator.EXPECT().Ingest(gomock.Any(), body).Return(nil, errors.New("")),
+					f.keys.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "7b2b84b6").Return(nil),
+				)
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				creator: mock_create.NewMockOrderCreator(ctrl),
+				orders:  mock_create.NewMockOrderGetter(ctrl),
+				keys:    mock_create.NewMockIdempotencyKeys(ctrl),
+			}
+
+			if tc.prepare != nil {
+				tc.prepare(&f)
+			}
+
+			req := httptest.NewRequest("POST", "/orders", bytes.NewReader(body))
+			if tc.key != "" {
+				req.Header.Set(create.IdempotencyKeyHeader, tc.key)
+			}
+
+			rec := httptest.NewRecorder()
+
+			create.New(logging.Discard(), f.creator, f.orders, f.keys, claimTimeout).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+			assert.Equal(t, tc.replayed, rec.Header().Get(create.ReplayedHeader) == "true")
+
+			if tc.respErr != "" {
+				var resp struct {
+					http_server.Response
+					Fields domain.ValidationErrors `json:"fields"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tc.respErr, resp.Error)
+				assert.Equal(t, tc.respFields, resp.Fields)
+				return
+			}
+
+			var order domain.Order
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+			assert.Equal(t, created.OrderUid, order.OrderUid)
+			assert.Equal(t, domain.StatusCreated, order.Status)
+		})
+	}
+}
+
+func Test_RunKeyPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	purger := mock_create.NewMockKeyPurger(ctrl)
+	gomock.InOrder(
+		purger.EXPECT().PurgeIdempotencyKeys(gomock.Any(), 24*time.Hour).Return(int64(0), errors.New("connection refused")),
+		purger.EXPECT().PurgeIdempotencyKeys(gomock.Any(), 24*time.Hour).DoAndReturn(func(context.Context, time.Duration) (int64, error) {
+			cancel()
+			return 3, nil
+		}),
+	)
+
+	// a failed purge is retried on the next tick
+	create.RunKeyPurge(ctx, logging.Discard(), purger, 24*time.Hour, time.Millisecond)
+}
@@ -0,0 +1,199 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/http-server/handlers/order/create/create.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/order/create/create.go -destination=internal/http-server/handlers/order/create/mocks/order_creator.go
+//
+
+// Package mock_create is a generated GoMock package.
+package mock_create
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "test-task/order-service/internal/domain"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderCreator is a mock of OrderCreator interface.
+type MockOrderCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderCreatorMockRecorder
+}
+
+// MockOrderCreatorMockRecorder is the mock recorder for MockOrderCreator.
+type MockOrderCreatorMockRecorder struct {
+	mock *MockOrderCreator
+}
+
+// NewMockOrderCreator creates a new mock instance.
+func NewMockOrderCreator(ctrl *gomock.Controller) *MockOrderCreator {
+	mock := &MockOrderCreator{ctrl: ctrl}
+	mock.recorder = &MockOrderCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderCreator) EXPECT() *MockOrderCreatorMockRecorder {
+	return m.recorder
+}
+
+// Ingest mocks base method.
+func (m *MockOrderCreator) Ingest(ctx context.Context, data []byte) (*domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ingest", ctx, data)
+	ret0, _ := ret[0].(*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ingest indicates an expected call of Ingest.
+func (mr *MockOrderCreatorMockRecorder) Ingest(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ingest", reflect.TypeOf((*MockOrderCreator)(nil).Ingest), ctx, data)
+}
+
+// MockOrderGetter is a mock of OrderGetter interface.
+type MockOrderGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderGetterMockRecorder
+}
+
+// MockOrderGetterMockRecorder is the mock recorder for MockOrderGetter.
+type MockOrderGetterMockRecorder struct {
+	mock *MockOrderGetter
+}
+
+// NewMockOrderGetter creates a new mock instance.
+func NewMockOrderGetter(ctrl *gomock.Controller) *MockOrderGetter {
+	mock := &MockOrderGetter{ctrl: ctrl}
+	mock.recorder = &MockOrderGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderGetter) EXPECT() *MockOrderGetterMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockOrderGetter) Get(ctx context.Context, orderId string) (*domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, orderId)
+	ret0, _ := ret[0].(*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrderGetterMockRecorder) Get(ctx, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderGetter)(nil).Get), ctx, orderId)
+}
+
+// MockIdempotencyKeys is a mock of IdempotencyKeys interface.
+type MockIdempotencyKeys struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyKeysMockRecorder
+}
+
+// MockIdempotencyKeysMockRecorder is the mock recorder for MockIdempotencyKeys.
+type MockIdempotencyKeysMockRecorder struct {
+	mock *MockIdempotencyKeys
+}
+
+// NewMockIdempotencyKeys creates a new mock instance.
+func NewMockIdempotencyKeys(ctrl *gomock.Controller) *MockIdempotencyKeys {
+	mock := &MockIdempotencyKeys{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyKeysMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyKeys) EXPECT() *MockIdempotencyKeysMockRecorder {
+	return m.recorder
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockIdempotencyKeys) ClaimIdempotencyKey(ctx context.Context, key, requestHash string, staleAfter time.Duration) (*domain.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, key, requestHash, staleAfter)
+	ret0, _ := ret[0].(*domain.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockIdempotencyKeysMockRecorder) ClaimIdempotencyKey(ctx, key, requestHash, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeys)(nil).ClaimIdempotencyKey), ctx, key, requestHash, staleAfter)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockIdempotencyKeys) CompleteIdempotencyKey(ctx context.Context, key, orderId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, key, orderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockIdempotencyKeysMockRecorder) CompleteIdempotencyKey(ctx, key, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeys)(nil).CompleteIdempotencyKey), ctx, key, orderId)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockIdempotencyKeys) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockIdempotencyKeysMockRecorder) ReleaseIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeys)(nil).ReleaseIdempotencyKey), ctx, key)
+}
+
+// MockKeyPurger is a mock of KeyPurger interface.
+type MockKeyPurger struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyPurgerMockRecorder
+}
+
+// MockKeyPurgerMockRecorder is the mock recorder for MockKeyPurger.
+type MockKeyPurgerMockRecorder struct {
+	mock *MockKeyPurger
+}
+
+// NewMockKeyPurger creates a new mock instance.
+func NewMockKeyPurger(ctrl *gomock.Controller) *MockKeyPurger {
+	mock := &MockKeyPurger{ctrl: ctrl}
+	mock.recorder = &MockKeyPurgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyPurger) EXPECT() *MockKeyPurgerMockRecorder {
+	return m.recorder
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockKeyPurger) PurgeIdempotencyKeys(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockKeyPurgerMockRecorder) PurgeIdempotencyKeys(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockKeyPurger)(nil).PurgeIdempotencyKeys), ctx, olderThan)
+}
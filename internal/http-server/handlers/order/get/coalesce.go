@@ -0,0 +1,156 @@
+package get
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// notFoundCapacity bounds the remembered missing orders
+const notFoundCapacity = 10000
+
+// Coalescing shares one lookup among concurrent lookups of the same
+// order, and remembers orders found missing for a while
+type Coalescing struct {
+	getter   OrderGetter
+	group    singleflight.Group
+	notFound *notFound
+}
+
+// notFound remembers missing orders until their entry expires. All the
+// entries live as long, so once full the oldest one is dropped
+type notFound struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	order *list.List
+	index map[string]*list.Element
+}
+
+type notFoundEntry struct {
+	orderId string
+	until   time.Time
+}
+
+func (n *notFound) has(orderId string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	e, ok := n.index[orderId]
+	if ok && time.Now().After(e.Value.(notFoundEntry).until) {
+		n.remove(e)
+		return false
+	}
+	return ok
+}
+
+func (n *notFound) add(orderId string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if e, ok := n.index[orderId]; ok {
+		n.remove(e)
+	}
+
+	if n.order.Len() >= notFoundCapacity {
+		n.remove(n.order.Front())
+	}
+
+	n.index[orderId] = n.order.PushBack(notFoundEntry{orderId: orderId, until: time.Now().Add(n.ttl)})
+}
+
+func (n *notFound) forget(orderId string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if e, ok := n.index[orderId]; ok {
+		n.remove(e)
+	}
+}
+
+// remove must be called with mu held
+func (n *notFound) remove(e *list.Element) {
+	n.order.Remove(e)
+	delete(n.index, e.Value.(notFoundEntry).orderId)
+}
+
+// NewCoalescing wraps getter, so concurrent lookups of the same order
+// share one call and lookups of an order found missing fail with
+// storage.ErrEntryDoesntExists for notFoundTTL without a call.
+// Zero notFoundTTL disables remembering missing orders
+func NewCoalescing(getter OrderGetter, notFoundTTL time.Duration) *Coalescing {
+	c := &Coalescing{getter: getter}
+
+	if notFoundTTL > 0 {
+		c.notFound = &notFound{
+			ttl:   notFoundTTL,
+			order: list.New(),
+			index: make(map[string]*list.Element),
+		}
+	}
+
+	return c
+}
+
+// Forget drops the order from the missing ones, once it has been stored
+func (c *Coalescing) Forget(orderId string) {
+	if c.notFound != nil {
+		c.notFound.forget(orderId)
+	}
+}
+
+// Forgetting wraps the cache of the ingest path, so orders stored by
+// this instance are forgotten as missing whatever the write policy.
+// Orders stored by other instances are found once their entry expires
+func (c *Coalescing) Forgetting(ingest cache.Cache[string, *domain.Order]) cache.Cache[string, *domain.Order] {
+	return forgetting{Cache: ingest, coalescing: c}
+}
+
+type forgetting struct {
+	cache.Cache[string, *domain.Order]
+	coalescing *Coalescing
+}
+
+func (f forgetting) Add(orderId string, order *domain.Order) bool {
+	f.coalescing.Forget(orderId)
+	return f.Cache.Add(orderId, order)
+}
+
+func (c *Coalescing) Get(ctx context.Context, orderId string) (*domain.Order, error) {
+	if c.notFound != nil {
+		if c.notFound.has(orderId) {
+			metrics.NotFoundCacheHits.Inc()
+			return nil, storage.ErrEntryDoesntExists
+		}
+	}
+
+	// the shared call outlives a caller that gives up,
+	// the others are still waiting for it
+	ch := c.group.DoChan(orderId, func() (interface{}, error) {
+		order, err := c.getter.Get(context.WithoutCancel(ctx), orderId)
+		if errors.Is(err, storage.ErrEntryDoesntExists) && c.notFound != nil {
+			c.notFound.add(orderId)
+		}
+		return order, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			metrics.CoalescedLookups.Inc()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*domain.Order), nil
+	}
+}
@@ -0,0 +1,223 @@
+package get_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	mock_cache "test-task/order-service/internal/cache/mocks"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/http-server/handlers/order/get"
+	mock_get "test-task/order-service/internal/http-server/handlers/order/get/mocks"
+	"test-task/order-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_CoalescingSharesLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderId := "b563feb7b2b84b64c8w"
+	want := &domain.Order{OrderUid: orderId}
+
+	const callers = 10
+
+	var (
+		started sync.WaitGroup
+		release = make(chan struct{})
+	)
+	started.Add(callers)
+
+	orderGetter := mock_get.NewMockOrderGetter(ctrl)
+	orderGetter.EXPECT().Get(gomock.Any(), orderId).DoAndReturn(func(ctx context.Context, orderId string) (*domain.Order, error) {
+		<-release
+		return want, nil
+	}).Times(1)
+
+	getter := get.NewCoalescing(orderGetter, time.Minute)
+
+	var done sync.WaitGroup
+	done.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer done.Done()
+			started.Done()
+
+			order, err := getter.Get(context.Background(), orderId)
+			assert.NoError(t, err)
+			assert.Equal(t, want, order)
+		}()
+	}
+
+	// letting every caller reach the shared lookup before it returns
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	done.Wait()
+}
+
+func Test_CoalescingNotFound(t *testing.T) {
+	test_cases := []struct {
+		test_name   string
+		notFoundTTL time.Duration
+		wait        time.Duration
+		lookups     int
+	}{
+		{
+			test_name:   "Missing order is remembered",
+			notFoundTTL: time.Minute,
+			lookups:     1,
+		},
+		{
+			test_name:   "Missing order is forgotten after TTL",
+			notFoundTTL: 10 * time.Millisecond,
+			wait:        20 * time.Millisecond,
+			lookups:     2,
+		},
+		{
+			test_name: "Zero TTL disables remembering",
+			lookups:   2,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			orderId := "9650f7fa5b404c2f999"
+
+			orderGetter := mock_get.NewMockOrderGetter(ctrl)
+			orderGetter.EXPECT().Get(gomock.Any(), orderId).
+				Return(nil, storage.ErrEntryDoesntExists).
+				Times(tc.lookups)
+
+			getter := get.NewCoalescing(orderGetter, tc.notFoundTTL)
+
+			_, err := getter.Get(context.Background(), orderId)
+			assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+
+			time.Sleep(tc.wait)
+
+			_, err = getter.Get(context.Background(), orderId)
+			assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+		})
+	}
+}
+
+func Test_CoalescingErrorsAreNotRemembered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderId := "9650f7fa5b404c2f123"
+
+	orderGetter := mock_get.NewMockOrderGetter(ctrl)
+	orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(nil, errors.New("connection refused")).Times(2)
+
+	getter := get.NewCoalescing(orderGetter, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := getter.Get(context.Background(), orderId)
+		assert.Error(t, err)
+	}
+}
+
+func Test_CoalescingCallerGivesUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderId := "b563feb7b2b84b64c8w"
+	release := make(chan struct{})
+
+	orderGetter := mock_get.NewMockOrderGetter(ctrl)
+	orderGetter.EXPECT().Get(gomock.Any(), orderId).DoAndReturn(func(ctx context.Context, orderId string) (*domain.Order, error) {
+		<-release
+		return &domain.Order{OrderUid: orderId}, ctx.Err()
+	}).Times(1)
+
+	getter := get.NewCoalescing(orderGetter, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := getter.Get(ctx, orderId)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the shared lookup is not cancelled with the caller who started it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		order, err := getter.Get(context.Background(), orderId)
+		assert.NoError(t, err)
+		assert.Equal(t, orderId, order.OrderUid)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-done
+}
+
+func Test_CoalescingForgetsStoredOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderId := "9650f7fa5b404c2f999"
+	order := &domain.Order{OrderUid: orderId}
+
+	orderGetter := mock_get.NewMockOrderGetter(ctrl)
+	gomock.InOrder(
+		orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(nil, storage.ErrEntryDoesntExists),
+		orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(order, nil),
+	)
+
+	ingestCache := mock_cache.NewMockCache[string, *domain.Order](ctrl)
+	ingestCache.EXPECT().Add(orderId, order).Return(false)
+
+	getter := get.NewCoalescing(orderGetter, time.Minute)
+
+	_, err := getter.Get(context.Background(), orderId)
+	assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+
+	// stored by the ingest path, even if the write policy doesn't cache it
+	getter.Forgetting(ingestCache).Add(orderId, order)
+
+	got, err := getter.Get(context.Background(), orderId)
+	assert.NoError(t, err)
+	assert.Equal(t, order, got)
+}
+
+func Test_CoalescingDropsOldestMissing(t *testing.T) {
+	const capacity = 10000
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oldest := fmt.Sprintf("%019d", 0)
+
+	orderGetter := mock_get.NewMockOrderGetter(ctrl)
+	orderGetter.EXPECT().Get(gomock.Any(), oldest).Return(nil, storage.ErrEntryDoesntExists).Times(2)
+	orderGetter.EXPECT().Get(gomock.Any(), gomock.Not(oldest)).Return(nil, storage.ErrEntryDoesntExists).Times(capacity)
+
+	getter := get.NewCoalescing(orderGetter, time.Minute)
+
+	for i := 0; i <= capacity; i++ {
+		_, err := getter.Get(context.Background(), fmt.Sprintf("%019d", i))
+		assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+	}
+
+	// the oldest entry made room for the last one, which is still remembered
+	_, err := getter.Get(context.Background(), oldest)
+	assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+	_, err = getter.Get(context.Background(), fmt.Sprintf("%019d", capacity))
+	assert.ErrorIs(t, err, storage.ErrEntryDoesntExists)
+}
@@ -2,13 +2,13 @@ package get
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"test-task/order-service/internal/cache"
 	"test-task/order-service/internal/domain"
 	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
 	"test-task/order-service/internal/storage"
 
 	"github.com/gorilla/mux"
@@ -18,61 +18,45 @@ type OrderGetter interface {
 	Get(ctx context.Context, orderId string) (*domain.Order, error)
 }
 
-func New(log *log.Logger, orderGetter OrderGetter, cache cache.Cache) http.HandlerFunc {
+func New(log *slog.Logger, orderGetter OrderGetter, cache cache.Cache[string, *domain.Order]) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.order.get.New"
 
+		log := log.With(slog.String("op", op))
+
 		uid := mux.Vars(r)["order_uid"]
 
 		if uid == "" {
-			log.Printf("%s: id is incorrect", op)
-			RespondWithError(errors.New("id is empty"), w, r, "invalid request", http.StatusBadRequest)
+			log.WarnContext(r.Context(), "id is incorrect")
+			http_server.RespondWithError(errors.New("id is empty"), w, r, "invalid request", http.StatusBadRequest)
 			return
 		}
 
 		// looking for order in cache
-		resOrder := cache.Get(uid)
-
-		if resOrder != nil {
-			log.Printf("got order from cache with id: [%s]", uid)
-			RespondOK(resOrder, w, r)
+		if resOrder, ok := cache.Get(uid); ok {
+			log.DebugContext(r.Context(), "got order from cache", slog.String("order_uid", uid))
+			http_server.RespondOK(resOrder, w, r)
 			return
 		}
 
 		resOrder, err := orderGetter.Get(r.Context(), uid)
 
 		if errors.Is(err, storage.ErrEntryDoesntExists) {
-			log.Printf("%s: order with id: [%s] not found", op, uid)
-			RespondWithError(err, w, r, "not found", http.StatusNotFound)
+			log.InfoContext(r.Context(), "order not found", slog.String("order_uid", uid))
+			http_server.RespondWithError(err, w, r, "not found", http.StatusNotFound)
 			return
 		}
 
 		if err != nil {
-			log.Printf("%s: failed to get order with id: [%s] error: %v", op, uid, err)
-			RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			log.ErrorContext(r.Context(), "failed to get order", slog.String("order_uid", uid), logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
 			return
 		}
 
-		log.Printf("got order with id: [%s]", uid)
+		log.DebugContext(r.Context(), "got order", slog.String("order_uid", uid))
 
 		// adding element to cache
 		cache.Add(uid, resOrder)
-		RespondOK(resOrder, w, r)
+		http_server.RespondOK(resOrder, w, r)
 	}
 }
-
-func RespondOK(data any, w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(data)
-}
-
-func RespondWithError(err error, w http.ResponseWriter, r *http.Request, msg string, status int) {
-	log.Printf("error: %s", err)
-
-	resp := http_server.Error(msg)
-
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(resp)
-}
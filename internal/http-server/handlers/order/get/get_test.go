@@ -4,24 +4,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http/httptest"
 	mock_cache "test-task/order-service/internal/cache/mocks"
 	"test-task/order-service/internal/domain"
 	http_server "test-task/order-service/internal/http-server"
 	"test-task/order-service/internal/http-server/handlers/order/get"
 	mock_get "test-task/order-service/internal/http-server/handlers/order/get/mocks"
+	"test-task/order-service/internal/logging"
 	"test-task/order-service/internal/storage"
 	"testing"
 
-	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func Test_GetHandler(t *testing.T) {
 	type fields struct {
-		cache       *mock_cache.MockCache
+		cache       *mock_cache.MockCache[string, *domain.Order]
 		orderGetter *mock_get.MockOrderGetter
 	}
 
@@ -43,7 +43,7 @@ func Test_GetHandler(t *testing.T) {
 				orderId := "b563feb7b2b84b64c8w"
 				want := &domain.Order{OrderUid: "b563feb7b2b84b64c8w"}
 				gomock.InOrder(
-					f.cache.EXPECT().Get(orderId).Return(nil),
+					f.cache.EXPECT().Get(orderId).Return(nil, false),
 					f.orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(want, nil),
 					f.cache.EXPECT().Add(orderId, want),
 				)
@@ -57,7 +57,7 @@ func Test_GetHandler(t *testing.T) {
 			prepare: func(f *fields) {
 				orderId := "9650f7fa5b404c2f996"
 				fromCache := &domain.Order{OrderUid: "9650f7fa5b404c2f996"}
-				f.cache.EXPECT().Get(orderId).Return(fromCache)
+				f.cache.EXPECT().Get(orderId).Return(fromCache, true)
 			},
 		},
 		{
@@ -68,7 +68,7 @@ func Test_GetHandler(t *testing.T) {
 			prepare: func(f *fields) {
 				orderId := "9650f7fa5b404c2f999"
 				gomock.InOrder(
-					f.cache.EXPECT().Get(orderId).Return(nil),
+					f.cache.EXPECT().Get(orderId).Return(nil, false),
 					f.orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(nil, storage.ErrEntryDoesntExists),
 				)
 			},
@@ -81,7 +81,7 @@ func Test_GetHandler(t *testing.T) {
 			prepare: func(f *fields) {
 				orderId := "9650f7fa5b404c2f123"
 				gomock.InOrder(
-					f.cache.EXPECT().Get(orderId).Return(nil),
+					f.cache.EXPECT().Get(orderId).Return(nil, false),
 					f.orderGetter.EXPECT().Get(gomock.Any(), orderId).Return(nil, errors.New("")),
 				)
 			},
@@ -97,10 +97,10 @@ func Test_GetHandler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			log := log.Default()
+			log := logging.Discard()
 
 			f := fields{
-				cache:       mock_cache.NewMockCache(ctrl),
+				cache:       mock_cache.NewMockCache[string, *domain.Order](ctrl),
 				orderGetter: mock_get.NewMockOrderGetter(ctrl),
 			}
 
@@ -1,5 +1,10 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: internal/http-server/handlers/order/get/get.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/order/get/get.go -destination=internal/http-server/handlers/order/get/mocks/order_getter.go
+//
 
 // Package mock_get is a generated GoMock package.
 package mock_get
@@ -9,7 +14,7 @@ import (
 	reflect "reflect"
 	domain "test-task/order-service/internal/domain"
 
-	gomock "github.com/golang/mock/gomock"
+	gomock "go.uber.org/mock/gomock"
 )
 
 // MockOrderGetter is a mock of OrderGetter interface.
@@ -45,7 +50,7 @@ func (m *MockOrderGetter) Get(ctx context.Context, orderId string) (*domain.Orde
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockOrderGetterMockRecorder) Get(ctx, orderId interface{}) *gomock.Call {
+func (mr *MockOrderGetterMockRecorder) Get(ctx, orderId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderGetter)(nil).Get), ctx, orderId)
 }
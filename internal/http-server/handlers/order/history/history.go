@@ -0,0 +1,52 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+
+	"github.com/gorilla/mux"
+)
+
+type StatusHistoryGetter interface {
+	StatusHistory(ctx context.Context, orderId string) ([]domain.StatusChange, error)
+}
+
+func New(log *slog.Logger, historyGetter StatusHistoryGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.order.history.New"
+
+		log := log.With(slog.String("op", op))
+
+		uid := mux.Vars(r)["order_uid"]
+
+		if uid == "" {
+			log.WarnContext(r.Context(), "id is incorrect")
+			http_server.RespondWithError(errors.New("id is empty"), w, r, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		history, err := historyGetter.StatusHistory(r.Context(), uid)
+
+		if errors.Is(err, storage.ErrEntryDoesntExists) {
+			log.InfoContext(r.Context(), "order not found", slog.String("order_uid", uid))
+			http_server.RespondWithError(err, w, r, "not found", http.StatusNotFound)
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(r.Context(), "failed to get status history", slog.String("order_uid", uid), logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		log.DebugContext(r.Context(), "got status history", slog.String("order_uid", uid))
+
+		http_server.RespondOK(history, w, r)
+	}
+}
@@ -0,0 +1,100 @@
+package history_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"test-task/order-service/internal/domain"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/http-server/handlers/order/history"
+	mock_history "test-task/order-service/internal/http-server/handlers/order/history/mocks"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_HistoryHandler(t *testing.T) {
+	changes := []domain.StatusChange{
+		{OrderUid: "b563feb7b2b84b64c8w", To: domain.StatusCreated, ChangedAt: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+		{OrderUid: "b563feb7b2b84b64c8w", From: domain.StatusCreated, To: domain.StatusPaid, ChangedAt: time.Date(2021, 11, 26, 7, 0, 0, 0, time.UTC)},
+	}
+
+	test_cases := []struct {
+		test_name  string
+		orderId    string
+		want       []domain.StatusChange
+		statusCode int
+		respErr    string
+		prepare    func(m *mock_history.MockStatusHistoryGetter)
+	}{
+		{
+			test_name:  "Success",
+			orderId:    "b563feb7b2b84b64c8w",
+			want:       changes,
+			statusCode: 200,
+			prepare: func(m *mock_history.MockStatusHistoryGetter) {
+				m.EXPECT().StatusHistory(gomock.Any(), "b563feb7b2b84b64c8w").Return(changes, nil)
+			},
+		},
+		{
+			test_name:  "An order with a non-existent ID",
+			orderId:    "9650f7fa5b404c2f999",
+			respErr:    "not found",
+			statusCode: 404,
+			prepare: func(m *mock_history.MockStatusHistoryGetter) {
+				m.EXPECT().StatusHistory(gomock.Any(), "9650f7fa5b404c2f999").Return(nil, storage.ErrEntryDoesntExists)
+			},
+		},
+		{
+			test_name:  "Internal Error",
+			orderId:    "9650f7fa5b404c2f123",
+			respErr:    "internal error",
+			statusCode: 500,
+			prepare: func(m *mock_history.MockStatusHistoryGetter) {
+				m.EXPECT().StatusHistory(gomock.Any(), "9650f7fa5b404c2f123").Return(nil, errors.New(""))
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			getter := mock_history.NewMockStatusHistoryGetter(ctrl)
+			tc.prepare(getter)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/orders/{order_uid:[a-z0-9]{19}}/history", history.New(logging.Discard(), getter)).Methods("GET")
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("/orders/%s/history", tc.orderId), nil)
+
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+
+			if tc.want != nil {
+				var got []domain.StatusChange
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Equal(t, tc.want, got)
+				return
+			}
+
+			var resp http_server.Response
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.respErr, resp.Error)
+		})
+	}
+}
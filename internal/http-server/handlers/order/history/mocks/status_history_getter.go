@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/http-server/handlers/order/history/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/order/history/history.go -destination=internal/http-server/handlers/order/history/mocks/status_history_getter.go
+//
+
+// Package mock_history is a generated GoMock package.
+package mock_history
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "test-task/order-service/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStatusHistoryGetter is a mock of StatusHistoryGetter interface.
+type MockStatusHistoryGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatusHistoryGetterMockRecorder
+}
+
+// MockStatusHistoryGetterMockRecorder is the mock recorder for MockStatusHistoryGetter.
+type MockStatusHistoryGetterMockRecorder struct {
+	mock *MockStatusHistoryGetter
+}
+
+// NewMockStatusHistoryGetter creates a new mock instance.
+func NewMockStatusHistoryGetter(ctrl *gomock.Controller) *MockStatusHistoryGetter {
+	mock := &MockStatusHistoryGetter{ctrl: ctrl}
+	mock.recorder = &MockStatusHistoryGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatusHistoryGetter) EXPECT() *MockStatusHistoryGetterMockRecorder {
+	return m.recorder
+}
+
+// StatusHistory mocks base method.
+func (m *MockStatusHistoryGetter) StatusHistory(ctx context.Context, orderId string) ([]domain.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, orderId)
+	ret0, _ := ret[0].([]domain.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockStatusHistoryGetterMockRecorder) StatusHistory(ctx, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockStatusHistoryGetter)(nil).StatusHistory), ctx, orderId)
+}
@@ -0,0 +1,87 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	http_server "test-task/order-service/internal/http-server"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+type OrderLister interface {
+	List(ctx context.Context, filter storage.ListFilter) (*storage.OrderPage, error)
+}
+
+func New(log *slog.Logger, orderLister OrderLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.order.list.New"
+
+		log := log.With(slog.String("op", op))
+
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			log.WarnContext(r.Context(), "invalid query", logging.Err(err))
+			http_server.RespondWithError(err, w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page, err := orderLister.List(r.Context(), filter)
+		if err != nil {
+			log.ErrorContext(r.Context(), "failed to list orders", logging.Err(err))
+			http_server.RespondWithError(err, w, r, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		log.DebugContext(r.Context(), "listed orders", slog.Int("count", len(page.Orders)))
+
+		http_server.RespondOK(page, w, r)
+	}
+}
+
+// parseFilter builds a storage filter from the query parameters:
+// customer_id, track_number, delivery_service, payment_provider,
+// date_from, date_to (RFC 3339), limit and cursor
+func parseFilter(q url.Values) (storage.ListFilter, error) {
+	filter := storage.ListFilter{
+		CustomerId:      q.Get("customer_id"),
+		TrackNumber:     q.Get("track_number"),
+		DeliveryService: q.Get("delivery_service"),
+		PaymentProvider: q.Get("payment_provider"),
+		Limit:           storage.DefaultListLimit,
+	}
+
+	var err error
+
+	if v := q.Get("date_from"); v != "" {
+		if filter.CreatedFrom, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("invalid date_from")
+		}
+	}
+
+	if v := q.Get("date_to"); v != "" {
+		if filter.CreatedTo, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("invalid date_to")
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit < 1 || filter.Limit > storage.MaxListLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", storage.MaxListLimit)
+		}
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		if filter.After, err = storage.DecodeCursor(v); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,129 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/http-server/handlers/order/list"
+	mock_list "test-task/order-service/internal/http-server/handlers/order/list/mocks"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_ListHandler(t *testing.T) {
+	cursor := storage.Cursor{
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OrderUid:    "b563feb7b2b84b64c8w",
+	}
+
+	test_cases := []struct {
+		test_name  string
+		query      string
+		want       *storage.OrderPage
+		statusCode int
+		respErr    string
+		prepare    func(m *mock_list.MockOrderLister)
+	}{
+		{
+			test_name:  "Filters and cursor",
+			query:      "?customer_id=test&payment_provider=wbpay&date_from=2021-11-01T00:00:00Z&limit=10&cursor=" + cursor.Encode(),
+			want:       &storage.OrderPage{Orders: []domain.Order{{OrderUid: "9650f7fa5b404c2f996"}}, NextCursor: "next"},
+			statusCode: 200,
+			prepare: func(m *mock_list.MockOrderLister) {
+				m.EXPECT().List(gomock.Any(), storage.ListFilter{
+					CustomerId:      "test",
+					PaymentProvider: "wbpay",
+					CreatedFrom:     time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+					After:           &cursor,
+					Limit:           10,
+				}).Return(&storage.OrderPage{Orders: []domain.Order{{OrderUid: "9650f7fa5b404c2f996"}}, NextCursor: "next"}, nil)
+			},
+		},
+		{
+			test_name:  "Default limit",
+			query:      "",
+			want:       &storage.OrderPage{Orders: []domain.Order{}},
+			statusCode: 200,
+			prepare: func(m *mock_list.MockOrderLister) {
+				m.EXPECT().List(gomock.Any(), storage.ListFilter{Limit: storage.DefaultListLimit}).
+					Return(&storage.OrderPage{Orders: []domain.Order{}}, nil)
+			},
+		},
+		{
+			test_name:  "Invalid date",
+			query:      "?date_to=yesterday",
+			respErr:    "invalid date_to",
+			statusCode: 400,
+		},
+		{
+			test_name:  "Limit out of range",
+			query:      "?limit=0",
+			respErr:    "limit must be between 1 and 1000",
+			statusCode: 400,
+		},
+		{
+			test_name:  "Invalid cursor",
+			query:      "?cursor=bm90LWpzb24",
+			respErr:    "invalid cursor",
+			statusCode: 400,
+		},
+		{
+			test_name:  "Internal Error",
+			query:      "?track_number=WBILMTESTTRACK",
+			respErr:    "internal error",
+			statusCode: 500,
+			prepare: func(m *mock_list.MockOrderLister) {
+				m.EXPECT().List(gomock.Any(), storage.ListFilter{TrackNumber: "WBILMTESTTRACK", Limit: storage.DefaultListLimit}).
+					Return(nil, errors.New(""))
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lister := mock_list.NewMockOrderLister(ctrl)
+
+			if tc.prepare != nil {
+				tc.prepare(lister)
+			}
+
+			router := mux.NewRouter()
+			router.HandleFunc("/orders", list.New(logging.Discard(), lister)).Methods("GET")
+
+			req := httptest.NewRequest("GET", "/orders"+tc.query, nil)
+
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.statusCode, rec.Code)
+
+			if tc.want != nil {
+				var page storage.OrderPage
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+				assert.Equal(t, tc.want, &page)
+				return
+			}
+
+			var resp struct {
+				Error string `json:"error"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.respErr, resp.Error)
+		})
+	}
+}
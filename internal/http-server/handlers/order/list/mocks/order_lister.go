@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/http-server/handlers/order/list/list.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/http-server/handlers/order/list/list.go -destination=internal/http-server/handlers/order/list/mocks/order_lister.go
+//
+
+// Package mock_list is a generated GoMock package.
+package mock_list
+
+import (
+	context "context"
+	reflect "reflect"
+	storage "test-task/order-service/internal/storage"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderLister is a mock of OrderLister interface.
+type MockOrderLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderListerMockRecorder
+}
+
+// MockOrderListerMockRecorder is the mock recorder for MockOrderLister.
+type MockOrderListerMockRecorder struct {
+	mock *MockOrderLister
+}
+
+// NewMockOrderLister creates a new mock instance.
+func NewMockOrderLister(ctrl *gomock.Controller) *MockOrderLister {
+	mock := &MockOrderLister{ctrl: ctrl}
+	mock.recorder = &MockOrderListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderLister) EXPECT() *MockOrderListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockOrderLister) List(ctx context.Context, filter storage.ListFilter) (*storage.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*storage.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderListerMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderLister)(nil).List), ctx, filter)
+}
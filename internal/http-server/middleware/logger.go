@@ -1,19 +1,37 @@
 package logger
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
-func New(log *log.Logger) func(next http.Handler) http.Handler {
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			startTime := time.Now()
 
-			next.ServeHTTP(w, r)
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
 
-			log.Printf("%s - %s (%s) duration=%s", r.Method, r.URL.Path, r.RemoteAddr, time.Since(startTime).String())
+			log.InfoContext(r.Context(), "request completed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.Int("status", rec.status),
+				slog.Duration("duration", time.Since(startTime)),
+			)
 		}
 
 		return http.HandlerFunc(fn)
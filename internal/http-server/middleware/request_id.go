@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"net/http"
+	"test-task/order-service/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID puts the request id into the request context, so every record
+// logged while serving the request carries it. The id is taken from
+// the X-Request-ID header when the client sets one
+func RequestID(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,55 @@
+package logger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	logger "test-task/order-service/internal/http-server/middleware"
+	"test-task/order-service/internal/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequestID(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		header    string
+	}{
+		{
+			test_name: "Generated",
+		},
+		{
+			test_name: "From header",
+			header:    "client-request-1",
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+
+			h := logger.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = logging.RequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/ping", nil)
+			if tc.header != "" {
+				req.Header.Set(logger.RequestIDHeader, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			assert.NotEmpty(t, got)
+			if tc.header != "" {
+				assert.Equal(t, tc.header, got)
+			}
+			assert.Equal(t, got, rec.Header().Get(logger.RequestIDHeader))
+		})
+	}
+}
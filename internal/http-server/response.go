@@ -1,5 +1,11 @@
 package http_server
 
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
 const (
 	StatusOK    = "OK"
 	StatusError = "Error"
@@ -16,3 +22,23 @@ func Error(msg string) Response {
 		Error:  msg,
 	}
 }
+
+func RespondOK(data any, w http.ResponseWriter, r *http.Request) {
+	Respond(data, http.StatusOK, w, r)
+}
+
+func Respond(data any, status int, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func RespondWithError(err error, w http.ResponseWriter, r *http.Request, msg string, status int) {
+	slog.DebugContext(r.Context(), "responding with error", slog.Int("status", status), slog.Any("error", err))
+
+	resp := Error(msg)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	messageKey
+)
+
+type message struct {
+	channel  string
+	sequence uint64
+}
+
+// New creates a leveled logger writing to stdout, format is "json" or "text".
+// Records logged with a context carry its request id or message sequence
+func New(level, format string) *slog.Logger {
+	return NewWithWriter(os.Stdout, level, format)
+}
+
+func NewWithWriter(w io.Writer, level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var h slog.Handler
+	if strings.EqualFold(format, "text") {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(contextHandler{h})
+}
+
+// Discard returns a logger dropping every record, handy in tests
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithMessage marks ctx as handling the NATS Streaming message with the given sequence
+func WithMessage(ctx context.Context, channel string, sequence uint64) context.Context {
+	return context.WithValue(ctx, messageKey, message{channel: channel, sequence: sequence})
+}
+
+// contextHandler adds correlation attributes stored in the record context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+
+	if msg, ok := ctx.Value(messageKey).(message); ok {
+		r.AddAttrs(slog.String("channel", msg.channel), slog.Uint64("msg_seq", msg.sequence))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
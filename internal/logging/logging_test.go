@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+
+	log := NewWithWriter(&buf, "debug", "json").With("op", "test")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithMessage(ctx, "order-notification", 42)
+
+	log.DebugContext(ctx, "handled")
+
+	var rec map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+
+	assert.Equal(t, "DEBUG", rec["level"])
+	assert.Equal(t, "handled", rec["msg"])
+	assert.Equal(t, "test", rec["op"])
+	assert.Equal(t, "req-1", rec["request_id"])
+	assert.Equal(t, "order-notification", rec["channel"])
+	assert.Equal(t, float64(42), rec["msg_seq"])
+}
+
+func Test_Level(t *testing.T) {
+	var buf bytes.Buffer
+
+	log := NewWithWriter(&buf, "warn", "text")
+
+	log.Info("skipped")
+	assert.Empty(t, buf.String())
+
+	log.Warn("written")
+	assert.Contains(t, buf.String(), "msg=written")
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Middleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/orders/{order_uid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	for _, uid := range []string{"b563feb7b2b84b64c8w", "9650f7fa5b404c2f996"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/"+uid, nil))
+	}
+
+	observer, err := HTTPRequestDuration.GetMetricWithLabelValues("/orders/{order_uid}", "GET", "404")
+	assert.NoError(t, err)
+
+	var m dto.Metric
+	assert.NoError(t, observer.(prometheus.Metric).Write(&m))
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+}
+
+func Test_HandlerExposesOrderConflicts(t *testing.T) {
+	OrderConflicts.Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "order_service_order_conflicts_total")
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "order_service"
+
+var (
+	MessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_received_total",
+		Help:      "Messages received from NATS Streaming.",
+	}, []string{"channel"})
+
+	MessagesProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_processed_total",
+		Help:      "Messages processed and acked, duplicates included.",
+	}, []string{"channel"})
+
+	// MessagesFailed is labeled with the failure outcome:
+	// retry (nacked for redelivery), dead_letter or conflict
+	MessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_failed_total",
+		Help:      "Messages that failed processing.",
+	}, []string{"channel", "outcome"})
+
+	OrderConflicts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_conflicts_total",
+		Help:      "Orders received again with a different payload for an existing order_uid.",
+	})
+
+	CacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_hits_total",
+		Help:      "Order lookups of the read path that found the order in the cache.",
+	})
+
+	CacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_misses_total",
+		Help:      "Order lookups of the read path that did not find the order in the cache.",
+	})
+
+	CacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_evictions_total",
+		Help:      "Entries evicted from the cache.",
+	})
+
+	CacheExpirations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_expirations_total",
+		Help:      "Entries removed from the cache once their TTL passed.",
+	})
+
+	// CacheRemoteRequests counts requests to the shared cache by request
+	// (add, get, remove) and result (ok, hit, miss, error)
+	CacheRemoteRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_remote_requests_total",
+		Help:      "Requests to the shared cache.",
+	}, []string{"request", "result"})
+
+	CoalescedLookups = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_lookups_coalesced_total",
+		Help:      "Order lookups served by a storage query shared with concurrent lookups.",
+	})
+
+	NotFoundCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_not_found_cache_hits_total",
+		Help:      "Lookups of orders recently found missing, answered without a storage query.",
+	})
+
+	StorageQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Duration of storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"op"})
+
+	BatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "order_batch_size",
+		Help:      "Number of orders saved by a single batch.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+	})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveQuery records the duration of a storage operation started at start,
+// meant to be deferred: defer metrics.ObserveQuery("get", time.Now())
+func ObserveQuery(op string, start time.Time) {
+	StorageQueryDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
@@ -1,5 +1,25 @@
 package nats_streaming
 
+// Message is a received message. It must be acked once it has been handled,
+// a nacked message is redelivered by the server after the ack wait expires
+type Message interface {
+	Data() []byte
+	Subject() string
+	Sequence() uint64
+	// Attempt is the delivery attempt number, starting from 1
+	Attempt() int
+	Ack() error
+	Nack() error
+}
+
 type Subscriber interface {
-	Subscribe() (func() error, error)
+	Subscribe() (<-chan Message, error)
+	// Stop closes the channel returned by Subscribe, keeping
+	// the connection open for acking messages already taken
+	Stop()
+	Close() error
+}
+
+type Publisher interface {
+	Publish(channel string, data []byte) error
 }
@@ -1,6 +1,66 @@
 package publisher
 
-/*
-type OrderProducer struct {
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/stan.go"
+)
+
+type Publisher struct {
+	sc   stan.Conn
+	lost atomic.Pointer[error]
+}
+
+func New(log *slog.Logger, nc *nats.Conn, clusterID, clientID string) (*Publisher, error) {
+	const op = "nats-streaming.publisher.New"
+
+	p := &Publisher{}
+
+	sc, err := stan.Connect(
+		clusterID,
+		clientID,
+		stan.NatsConn(nc),
+		stan.SetConnectionLostHandler(func(_ stan.Conn, reason error) {
+			log.Error("NATS publisher connection lost", slog.String("client_id", clientID), slog.Any("error", reason))
+			p.lost.Store(&reason)
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("%s: connecting to cluster: %w", op, err)
+	}
+
+	p.sc = sc
+
+	return p, nil
+}
+
+func (p *Publisher) Publish(channel string, data []byte) error {
+	const op = "nats-streaming.publisher.Publish"
+
+	if err := p.sc.Publish(channel, data); err != nil {
+		return fmt.Errorf("%s: publishing to %s: %w", op, channel, err)
+	}
+
+	return nil
+}
+
+// Check reports a lost or broken connection
+func (p *Publisher) Check(ctx context.Context) error {
+	if reason := p.lost.Load(); reason != nil {
+		return fmt.Errorf("connection lost: %w", *reason)
+	}
+
+	if nc := p.sc.NatsConn(); nc == nil || !nc.IsConnected() {
+		return errors.New("not connected")
+	}
+
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.sc.Close()
 }
-*/
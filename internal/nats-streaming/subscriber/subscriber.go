@@ -1,84 +1,214 @@
 package subscriber
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/stan.go"
 )
 
-const (
-	clusterID = "dev"
-	clientID  = "order-service"
-	channel   = "order-notification"
-)
+type Config struct {
+	ClusterID   string
+	ClientID    string
+	Channel     string
+	DurableName string
+	QueueGroup  string
+	MaxInflight int
+	AckWait     time.Duration
+	// MaxLag is how long received messages may wait for the consumer
+	// before the subscriber is reported unhealthy
+	MaxLag time.Duration
+}
 
 type orderSubscriber struct {
+	log      *slog.Logger
+	cfg      Config
 	sc       stan.Conn
 	sub      stan.Subscription
-	recvChan chan stan.Msg
+	recvChan chan nats_streaming.Message
+
+	// done unblocks handlers that are still sending into recvChan,
+	// mu makes Close wait for them before closing recvChan
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+
+	// lost is the reason the connection has been lost, waiting is the number
+	// of messages not taken from recvChan yet, lastHandoff is when one was taken last
+	lost        atomic.Pointer[error]
+	waiting     atomic.Int64
+	lastHandoff atomic.Int64
+}
+
+// message wraps stan.Msg, acking is left to the consumer
+type message struct {
+	msg *stan.Msg
 }
 
-func New(nc *nats.Conn) (*orderSubscriber, error) {
+func (m message) Data() []byte     { return m.msg.Data }
+func (m message) Subject() string  { return m.msg.Subject }
+func (m message) Sequence() uint64 { return m.msg.Sequence }
+func (m message) Attempt() int     { return int(m.msg.RedeliveryCount) + 1 }
+func (m message) Ack() error       { return m.msg.Ack() }
+
+// Nack leaves the message unacknowledged, so the server redelivers it after AckWait
+func (m message) Nack() error { return nil }
+
+func New(log *slog.Logger, nc *nats.Conn, cfg Config) (*orderSubscriber, error) {
 	const op = "nats-streaming.sub.New"
 
-	// Connect to NATS cluster
+	s := &orderSubscriber{
+		log:      log,
+		cfg:      cfg,
+		recvChan: make(chan nats_streaming.Message),
+		done:     make(chan struct{}),
+	}
+
+	// Connect to NATS cluster, a lost connection is reported by Check,
+	// so the health probes take the instance out of service
 	sc, err := stan.Connect(
-		clusterID,
-		clientID,
+		cfg.ClusterID,
+		cfg.ClientID,
 		stan.NatsConn(nc),
 		stan.SetConnectionLostHandler(func(_ stan.Conn, reason error) {
-			log.Fatalf("NATS Connection lost, reason: %v", reason)
+			log.Error("NATS connection lost", slog.String("client_id", cfg.ClientID), slog.Any("error", reason))
+			s.lost.Store(&reason)
 		}))
 	if err != nil {
 		return nil, fmt.Errorf("%s: connecting to cluster: %w", op, err)
 	}
 
-	log.Printf("Connected to %s clusterID: [%s] clientID: [%s]\n", nats.DefaultURL, clusterID, clientID)
+	log.Info("connected to cluster", slog.String("url", nc.ConnectedUrl()),
+		slog.String("cluster_id", cfg.ClusterID), slog.String("client_id", cfg.ClientID))
+
+	s.sc = sc
 
-	return &orderSubscriber{
-		sc:       sc,
-		recvChan: make(chan stan.Msg),
-	}, nil
+	return s, nil
 }
 
-func (s *orderSubscriber) Subscribe() (recvChan <-chan stan.Msg, err error) {
+func (s *orderSubscriber) Subscribe() (recvChan <-chan nats_streaming.Message, err error) {
 	const op = "nats-streaming.consumer.Subscribe"
 
-	// Subscribe with manual ack mode
-	aw, _ := time.ParseDuration("60s")
-	s.sub, err = s.sc.Subscribe(
-		channel,
-		func(msg *stan.Msg) {
-			msg.Ack()
-
-			// sending msg into the output channel
-			s.recvChan <- *msg
-		},
-		stan.MaxInflight(25),
+	// Subscribe with manual ack mode, messages are acked by the consumer
+	// after processing, otherwise redelivered after AckWait
+	opts := []stan.SubscriptionOption{
+		stan.MaxInflight(s.cfg.MaxInflight),
 		stan.SetManualAckMode(),
-		stan.AckWait(aw),
-	)
+		stan.AckWait(s.cfg.AckWait),
+	}
+	if s.cfg.DurableName != "" {
+		opts = append(opts, stan.DurableName(s.cfg.DurableName))
+	}
+
+	if s.cfg.QueueGroup != "" {
+		s.sub, err = s.sc.QueueSubscribe(s.cfg.Channel, s.cfg.QueueGroup, s.handle, opts...)
+	} else {
+		s.sub, err = s.sc.Subscribe(s.cfg.Channel, s.handle, opts...)
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("%s: subscribing to a channel: %w", op, err)
 	}
 
-	log.Printf("Subscribed to the channel: [%s] clientID: [%s]\n", channel, clientID)
+	s.lastHandoff.Store(time.Now().UnixNano())
+
+	s.log.Info("subscribed to the channel", slog.String("channel", s.cfg.Channel), slog.String("client_id", s.cfg.ClientID),
+		slog.String("durable", s.cfg.DurableName), slog.String("queue", s.cfg.QueueGroup))
 
 	return s.recvChan, nil
 }
 
-func (s *orderSubscriber) Close() {
-	if s.sc != nil {
-		s.sc.Close()
+func (s *orderSubscriber) handle(msg *stan.Msg) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	s.waiting.Add(1)
+	defer s.waiting.Add(-1)
+
+	// sending msg into the output channel
+	select {
+	case s.recvChan <- message{msg: msg}:
+		s.lastHandoff.Store(time.Now().UnixNano())
+	case <-s.done:
+	}
+}
+
+// Lag is how long the received messages have been waiting for the consumer,
+// zero when the consumer keeps up
+func (s *orderSubscriber) Lag() time.Duration {
+	if s.waiting.Load() == 0 {
+		return 0
 	}
+
+	return time.Since(time.Unix(0, s.lastHandoff.Load()))
+}
+
+// Check reports a lost or broken connection and a consumer lagging more than MaxLag
+func (s *orderSubscriber) Check(ctx context.Context) error {
+	if reason := s.lost.Load(); reason != nil {
+		return fmt.Errorf("connection lost: %w", *reason)
+	}
+
+	if nc := s.sc.NatsConn(); nc == nil || !nc.IsConnected() {
+		return errors.New("not connected")
+	}
+
+	if lag := s.Lag(); s.cfg.MaxLag > 0 && lag > s.cfg.MaxLag {
+		return fmt.Errorf("consumer lag %s exceeds %s", lag.Round(time.Second), s.cfg.MaxLag)
+	}
+
+	return nil
+}
+
+// Stop stops handing messages to the consumer and closes the output channel.
+// Messages received but not taken yet are left unacked, so the server
+// redelivers them, while those already taken can still be acked until Close
+func (s *orderSubscriber) Stop() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.recvChan)
+		s.mu.Unlock()
+	})
+}
+
+func (s *orderSubscriber) Close() error {
+	const op = "nats-streaming.consumer.Close"
+
+	s.Stop()
+
+	var errs []error
+
+	// Close keeps the durable interest on the server, unlike Unsubscribe,
+	// so the next start resumes from the last acked message
 	if s.sub != nil {
-		if err := s.sub.Unsubscribe(); err != nil {
-			log.Fatal(err)
+		if err := s.sub.Close(); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	close(s.recvChan)
+	if s.sc != nil {
+		if err := s.sc.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+// batcher merges orders saved concurrently by the workers into one
+// storage.SaveBatch call. The batch is flushed once it has size orders
+// or timeout after the first one was added, whichever comes first.
+// Save returns only after the batch has been committed, so messages are
+// acked after the commit. Each worker waits for its order, so a batch
+// never grows past the number of workers. A failed batch is saved again
+// order by order, so a bad order doesn't fail the others
+type batcher struct {
+	ctx     context.Context
+	log     *slog.Logger
+	db      storage.Storage
+	size    int
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending []pendingOrder
+	timer   *time.Timer
+}
+
+type pendingOrder struct {
+	order  domain.Order
+	result chan error
+}
+
+func newBatcher(ctx context.Context, log *slog.Logger, db storage.Storage, size int, timeout time.Duration) *batcher {
+	return &batcher{
+		ctx:     ctx,
+		log:     log,
+		db:      db,
+		size:    size,
+		timeout: timeout,
+	}
+}
+
+func (b *batcher) Save(order domain.Order) error {
+	result := make(chan error, 1)
+
+	b.mu.Lock()
+	b.pending = append(b.pending, pendingOrder{order: order, result: result})
+
+	var batch []pendingOrder
+	if len(b.pending) >= b.size {
+		batch = b.take()
+	} else if len(b.pending) == 1 {
+		b.timer = time.AfterFunc(b.timeout, b.flushPending)
+	}
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.flush(batch)
+	}
+
+	return <-result
+}
+
+// take must be called with mu held
+func (b *batcher) take() []pendingOrder {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	batch := b.pending
+	b.pending = nil
+
+	return batch
+}
+
+func (b *batcher) flushPending() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.flush(batch)
+	}
+}
+
+func (b *batcher) flush(batch []pendingOrder) {
+	const op = "service.batcher.flush"
+
+	metrics.BatchSize.Observe(float64(len(batch)))
+
+	orders := make([]domain.Order, len(batch))
+	for i, p := range batch {
+		orders[i] = p.order
+	}
+
+	// the batch is committed even if the service is stopping, its messages
+	// have been taken already, b.ctx is cancelled only by Service.Abort
+	ctx := b.ctx
+
+	results, err := b.db.SaveBatch(ctx, orders)
+	if err != nil {
+		b.log.Error("failed saving batch", slog.String("op", op), slog.Int("size", len(batch)), logging.Err(err))
+
+		if len(batch) == 1 {
+			batch[0].result <- fmt.Errorf("%s: %w", op, err)
+			return
+		}
+
+		// one bad order fails the whole transaction, saving the orders
+		// one by one fails only that order, the others are stored
+		for _, p := range batch {
+			p.result <- b.db.Save(ctx, p.order)
+		}
+		return
+	}
+
+	for i, p := range batch {
+		p.result <- results[i]
+	}
+}
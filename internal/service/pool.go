@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
+)
+
+// PartitionKey is the message field deciding which worker handles it
+type PartitionKey string
+
+const (
+	PartitionByOrder    PartitionKey = "order_uid"
+	PartitionByCustomer PartitionKey = "customer_id"
+)
+
+// run spreads the messages over the workers by partition key, so messages
+// with the same key are handled one by one in order of arrival, while
+// different keys are handled in parallel. No more messages are taken
+// from msgChan while MaxInflight of them are queued or being handled,
+// which holds the subscriber back until the workers catch up
+func (s *Service) run(msgChan <-chan nats_streaming.Message, handle func(msg nats_streaming.Message)) {
+	inflight := make(chan struct{}, s.cfg.MaxInflight)
+
+	var wg sync.WaitGroup
+
+	queues := make([]chan nats_streaming.Message, s.cfg.Workers)
+	for i := range queues {
+		queues[i] = make(chan nats_streaming.Message, s.cfg.MaxInflight)
+
+		wg.Add(1)
+		go func(queue <-chan nats_streaming.Message) {
+			defer wg.Done()
+			s.work(queue, inflight, handle)
+		}(queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case inflight <- struct{}{}:
+		case <-s.ctx.Done():
+			s.log.Info("context cancelled, stopped taking messages")
+			return
+		}
+
+		select {
+		case <-s.ctx.Done():
+			s.log.Info("context cancelled, stopped taking messages")
+			return
+		case msg, ok := <-msgChan:
+			if !ok {
+				s.log.Info("message channel closed")
+				return
+			}
+			queues[s.partition(msg.Data())] <- msg
+		}
+	}
+}
+
+func (s *Service) work(queue <-chan nats_streaming.Message, inflight <-chan struct{}, handle func(msg nats_streaming.Message)) {
+	for msg := range queue {
+		if s.ctx.Err() != nil {
+			// queued messages are not started once the service is stopping,
+			// the server redelivers them
+			_ = msg.Nack()
+		} else {
+			handle(msg)
+		}
+		<-inflight
+	}
+}
+
+// partition returns the worker index for the message. Messages without
+// the key, like status events partitioned by customer, fall back to order_uid
+func (s *Service) partition(data []byte) int {
+	if s.cfg.Workers == 1 {
+		return 0
+	}
+
+	var keys struct {
+		OrderUid   string `json:"order_uid"`
+		CustomerId string `json:"customer_id"`
+	}
+	// undecodable messages are invalid anyway, any worker dead-letters them
+	_ = json.Unmarshal(data, &keys)
+
+	key := keys.OrderUid
+	if s.cfg.PartitionKey == PartitionByCustomer && keys.CustomerId != "" {
+		key = keys.CustomerId
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(s.cfg.Workers))
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"test-task/order-service/internal/logging"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	key    string
+	data   []byte
+	seq    uint64
+	acked  atomic.Bool
+	nacked atomic.Bool
+}
+
+func (m *testMessage) Data() []byte     { return m.data }
+func (m *testMessage) Subject() string  { return "order-notification" }
+func (m *testMessage) Sequence() uint64 { return m.seq }
+func (m *testMessage) Attempt() int     { return 1 }
+func (m *testMessage) Ack() error       { m.acked.Store(true); return nil }
+func (m *testMessage) Nack() error      { m.nacked.Store(true); return nil }
+
+func newTestMessage(seq uint64, orderUid, customerId string) *testMessage {
+	return &testMessage{
+		key:  orderUid,
+		data: []byte(fmt.Sprintf(`{"order_uid":%q,"customer_id":%q}`, orderUid, customerId)),
+		seq:  seq,
+	}
+}
+
+func Test_RunKeepsKeyOrder(t *testing.T) {
+	s := New(context.Background(), logging.Discard(), nil, nil, nil, Config{Workers: 4, MaxInflight: 8})
+
+	keys := []string{"a", "b", "c", "d", "e"}
+	const perKey = 50
+
+	msgChan := make(chan nats_streaming.Message)
+	go func() {
+		seq := uint64(0)
+		for i := 0; i < perKey; i++ {
+			for _, key := range keys {
+				seq++
+				msgChan <- newTestMessage(seq, key, "")
+			}
+		}
+		close(msgChan)
+	}()
+
+	var (
+		mu      sync.Mutex
+		handled = make(map[string][]uint64)
+	)
+
+	s.run(msgChan, func(msg nats_streaming.Message) {
+		key := msg.(*testMessage).key
+
+		mu.Lock()
+		handled[key] = append(handled[key], msg.Sequence())
+		mu.Unlock()
+	})
+
+	for _, key := range keys {
+		seqs := handled[key]
+		assert.Len(t, seqs, perKey, key)
+		for i := 1; i < len(seqs); i++ {
+			assert.Less(t, seqs[i-1], seqs[i], "messages of %s reordered", key)
+		}
+	}
+}
+
+func Test_RunBoundsInflight(t *testing.T) {
+	const maxInflight = 3
+
+	s := New(context.Background(), logging.Discard(), nil, nil, nil, Config{Workers: 2, MaxInflight: maxInflight})
+
+	release := make(chan struct{})
+	msgChan := make(chan nats_streaming.Message)
+
+	var taken atomic.Int32
+	go func() {
+		for i := 0; i < 10; i++ {
+			msgChan <- newTestMessage(uint64(i+1), fmt.Sprintf("order%d", i), "")
+			taken.Add(1)
+		}
+		close(msgChan)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		s.run(msgChan, func(msg nats_streaming.Message) { <-release })
+		close(done)
+	}()
+
+	// the handlers are blocked, so only maxInflight messages can be taken
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(maxInflight), taken.Load())
+
+	close(release)
+	<-done
+	assert.Equal(t, int32(10), taken.Load())
+}
+
+func Test_RunNacksQueuedOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := New(ctx, logging.Discard(), nil, nil, nil, Config{Workers: 1, MaxInflight: 4})
+
+	first := newTestMessage(1, "a", "")
+	queued := newTestMessage(2, "a", "")
+
+	msgChan := make(chan nats_streaming.Message, 2)
+	msgChan <- first
+	msgChan <- queued
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		s.run(msgChan, func(msg nats_streaming.Message) {
+			if msg == first {
+				close(started)
+				<-release
+			}
+		})
+		close(done)
+	}()
+
+	<-started
+	// letting the dispatcher queue the second message
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	close(release)
+	<-done
+
+	assert.False(t, first.nacked.Load())
+	assert.True(t, queued.nacked.Load())
+}
+
+func Test_Partition(t *testing.T) {
+	byOrder := New(context.Background(), logging.Discard(), nil, nil, nil, Config{Workers: 16})
+	byCustomer := New(context.Background(), logging.Discard(), nil, nil, nil, Config{Workers: 16, PartitionKey: PartitionByCustomer})
+
+	// orders of the same customer stay on one worker only when partitioned by customer
+	a := newTestMessage(1, "order1", "customer").Data()
+	b := newTestMessage(2, "order2", "customer").Data()
+	assert.Equal(t, byCustomer.partition(a), byCustomer.partition(b))
+
+	seen := make(map[int]bool)
+	for i := 0; i < 32; i++ {
+		seen[byOrder.partition(newTestMessage(uint64(i), fmt.Sprintf("order%d", i), "customer").Data())] = true
+	}
+	assert.Greater(t, len(seen), 1)
+
+	// status events have no customer_id and fall back to order_uid
+	event := []byte(`{"order_uid":"order1","status":"paid"}`)
+	assert.Equal(t, byOrder.partition(a), byCustomer.partition(event))
+}
+
+func Test_AbortLeavesMessageUnacked(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := New(ctx, logging.Discard(), nil, nil, nil, Config{Workers: 1, MaxAttempts: 1})
+
+	msg := newTestMessage(1, "a", "")
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.handle(msg, func(ctx context.Context, _ []byte) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		close(done)
+	}()
+
+	<-started
+
+	// stopping the service doesn't cancel the message taken already
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("processing cancelled with the service")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Abort()
+	<-done
+
+	assert.False(t, msg.acked.Load())
+	assert.False(t, msg.nacked.Load())
+}
@@ -3,59 +3,225 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"test-task/order-service/internal/cache"
 	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/metrics"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
 	"test-task/order-service/internal/storage"
-
-	"github.com/go-playground/validator"
-	"github.com/nats-io/stan.go"
+	"time"
 )
 
+// ErrInvalidOrder marks messages that will never be processed successfully,
+// so there is no point in having them redelivered
+var ErrInvalidOrder = errors.New("invalid order")
+
+type DeadLetterQueue interface {
+	Put(ctx context.Context, msg nats_streaming.Message, reason error) error
+}
+
+const defaultBatchTimeout = 20 * time.Millisecond
+
+type Config struct {
+	MaxAttempts int
+	// Workers handle messages in parallel, messages with the same
+	// partition key are handled by the same worker in order of arrival
+	Workers int
+	// MaxInflight bounds the number of messages taken but not handled yet,
+	// it is meant to match the in-flight limit of the subscription
+	MaxInflight  int
+	PartitionKey PartitionKey
+	// BatchSize orders are saved in one transaction, waiting for
+	// BatchTimeout at most for the batch to fill up. Batching is off
+	// unless BatchSize is greater than 1
+	BatchSize    int
+	BatchTimeout time.Duration
+}
+
 type Service struct {
-	ctx context.Context
-	db  storage.Storage
+	ctx         context.Context
+	processCtx  context.Context
+	abort       context.CancelFunc
+	log         *slog.Logger
+	db          storage.Storage
+	cache       cache.Cache[string, *domain.Order]
+	deadLetters DeadLetterQueue
+	cfg         Config
+	batcher     *batcher
 }
 
-func New(ctx context.Context, db storage.Storage) *Service {
-	return &Service{
-		ctx: ctx,
-		db:  db,
+// New creates the service, orders it stores are added to the cache,
+// which is usually wrapped by cache.NewWriter to follow a write policy
+func New(ctx context.Context, log *slog.Logger, db storage.Storage, cache cache.Cache[string, *domain.Order], deadLetters DeadLetterQueue, cfg Config) *Service {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxInflight < cfg.Workers {
+		cfg.MaxInflight = cfg.Workers
+	}
+	if cfg.PartitionKey == "" {
+		cfg.PartitionKey = PartitionByOrder
+	}
+
+	// cancelling ctx stops taking messages, the ones taken already
+	// are processed on processCtx until Abort
+	processCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+
+	s := &Service{
+		ctx:         ctx,
+		processCtx:  processCtx,
+		abort:       abort,
+		log:         log,
+		db:          db,
+		cache:       cache,
+		deadLetters: deadLetters,
+		cfg:         cfg,
 	}
+
+	if cfg.BatchSize > 1 && cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = defaultBatchTimeout
+	}
+
+	if cfg.BatchSize > 1 {
+		s.batcher = newBatcher(processCtx, log, db, cfg.BatchSize, cfg.BatchTimeout)
+	}
+
+	return s
+}
+
+// Run handles order messages until msgChan is closed or the service ctx is done.
+// Messages being handled at that moment are finished before Run returns
+func (s *Service) Run(msgChan <-chan nats_streaming.Message) {
+	s.run(msgChan, s.HandleMessage)
+}
+
+// Abort cancels the processing of the messages being handled,
+// it is called when the shutdown deadline expires
+func (s *Service) Abort() {
+	s.abort()
+}
+
+// HandleMessage processes the message and acks it once the order is stored.
+// Failed messages are nacked for redelivery until maxAttempts is reached,
+// invalid ones and those out of attempts go to the dead-letter queue
+func (s *Service) HandleMessage(msg nats_streaming.Message) {
+	s.handle(msg, s.ProcessMessage)
 }
 
-func (s *Service) Run(msgChan <-chan stan.Msg) {
-	for {
-		select {
-		case <-s.ctx.Done():
-			log.Println("Context cancelled")
-		case msg := <-msgChan:
-			if err := s.ProcessMessage(msg); err != nil {
-				log.Println("Error: processing message:", err)
-			}
+func (s *Service) handle(msg nats_streaming.Message, process func(ctx context.Context, data []byte) error) {
+	channel := msg.Subject()
+	metrics.MessagesReceived.WithLabelValues(channel).Inc()
+
+	// every record logged while processing carries the message sequence.
+	// Cancelling the service doesn't abort the message taken already,
+	// only Abort does once the shutdown deadline expires
+	ctx := logging.WithMessage(s.processCtx, channel, msg.Sequence())
+
+	err := process(ctx, msg.Data())
+
+	switch {
+	case err != nil && s.processCtx.Err() != nil:
+		// neither acked nor nacked, the server redelivers it after the ack wait
+		s.log.WarnContext(ctx, "processing aborted, message left for redelivery", logging.Err(err))
+		return
+	case err == nil:
+		metrics.MessagesProcessed.WithLabelValues(channel).Inc()
+		s.ack(ctx, msg)
+		return
+	case errors.Is(err, storage.ErrEntryConflict):
+		// the conflicting payload has been recorded by the storage
+		metrics.OrderConflicts.Inc()
+		metrics.MessagesFailed.WithLabelValues(channel, "conflict").Inc()
+		s.log.WarnContext(ctx, "conflicting redelivery", logging.Err(err))
+		s.ack(ctx, msg)
+		return
+	case errors.Is(err, storage.ErrEntryAlreadyExists):
+		metrics.MessagesProcessed.WithLabelValues(channel).Inc()
+		s.log.InfoContext(ctx, "duplicate message skipped")
+		s.ack(ctx, msg)
+		return
+	}
+
+	s.log.ErrorContext(ctx, "failed processing message", slog.Int("attempt", msg.Attempt()), logging.Err(err))
+
+	if !errors.Is(err, ErrInvalidOrder) && msg.Attempt() < s.cfg.MaxAttempts {
+		metrics.MessagesFailed.WithLabelValues(channel, "retry").Inc()
+		if err := msg.Nack(); err != nil {
+			s.log.ErrorContext(ctx, "failed nacking message", logging.Err(err))
 		}
+		return
+	}
+
+	metrics.MessagesFailed.WithLabelValues(channel, "dead_letter").Inc()
+
+	if err := s.deadLetters.Put(ctx, msg, err); err != nil {
+		// keeping the message unacked, so it is not lost
+		s.log.ErrorContext(ctx, "failed dead-lettering message", logging.Err(err))
+		_ = msg.Nack()
+		return
+	}
+
+	s.ack(ctx, msg)
+}
+
+func (s *Service) ack(ctx context.Context, msg nats_streaming.Message) {
+	if err := msg.Ack(); err != nil {
+		s.log.ErrorContext(ctx, "failed acking message", logging.Err(err))
 	}
 }
 
-func (s *Service) ProcessMessage(msg stan.Msg) error {
-	const op = "service.ProcessMessage"
+// ProcessMessage stores the order in data, it is how orders from
+// the channel are ingested
+func (s *Service) ProcessMessage(ctx context.Context, data []byte) error {
+	_, err := s.Ingest(ctx, data)
+	return err
+}
+
+// Ingest validates and stores the order in data, adding it to the cache.
+// Every way of creating orders goes through it. Malformed and invalid
+// orders fail with ErrInvalidOrder, duplicates fail with the errors of
+// storage.Storage.Save
+func (s *Service) Ingest(ctx context.Context, data []byte) (*domain.Order, error) {
+	const op = "service.Ingest"
 
 	var order domain.Order
-	err := json.Unmarshal(msg.Data, &order)
+	err := json.Unmarshal(data, &order)
 
 	if err != nil {
-		return fmt.Errorf("%s: failed unmarshalling data: %w", op, err)
+		return nil, fmt.Errorf("%s: failed unmarshalling data: %w: %w", op, ErrInvalidOrder, err)
 	}
 
-	if err := validator.New().Struct(order); err != nil {
-		return fmt.Errorf("%s: invalid data: %w", op, err.(validator.ValidationErrors))
+	if err := order.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid data: %w: %w", op, ErrInvalidOrder, err)
 	}
 
-	if err = s.db.Save(s.ctx, order); err != nil {
-		return fmt.Errorf("%s: saving order: %w", op, err)
+	// a new order starts its lifecycle as created, later statuses
+	// are only reached through status events
+	if order.Status != "" && order.Status != domain.StatusCreated {
+		err := domain.ValidationErrors{{Field: "status", Rule: "eq", Param: string(domain.StatusCreated)}}
+		return nil, fmt.Errorf("%s: invalid data: %w: %w", op, ErrInvalidOrder, err)
 	}
 
-	log.Printf("Message with order_uid: [%s] has been saved", order.OrderUid)
+	// the cached order has the status the storage gives a new order
+	order.Status = domain.StatusCreated
+
+	if err = s.save(ctx, order); err != nil {
+		return nil, fmt.Errorf("%s: saving order: %w", op, err)
+	}
 
-	return nil
+	s.cache.Add(order.OrderUid, &order)
+
+	s.log.InfoContext(ctx, "order saved", slog.String("order_uid", order.OrderUid))
+
+	return &order, nil
+}
+
+func (s *Service) save(ctx context.Context, order domain.Order) error {
+	if s.batcher != nil {
+		return s.batcher.Save(order)
+	}
+	return s.db.Save(ctx, order)
 }
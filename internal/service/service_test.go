@@ -0,0 +1,494 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	mock_cache "test-task/order-service/internal/cache/mocks"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
+	mock_nats_streaming "test-task/order-service/internal/nats-streaming/mocks"
+	"test-task/order-service/internal/service"
+	mock_service "test-task/order-service/internal/service/mocks"
+	"test-task/order-service/internal/storage"
+	mock_storage "test-task/order-service/internal/storage/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const maxAttempts = 3
+
+const validOrder = `{
+	"order_uid": "b563feb7b2b84b64c8w",
+	"track_number": "WBILMTESTTRACK",
+	"entry": "WBIL",
+	"delivery": {
+		"name": "Test Testov",
+		"phone": "+9720000000",
+		"zip": "2639809",
+		"city": "Kiryat Mozkin",
+		"address": "Ploshad Mira 15",
+		"region": "Kraiot",
+		"email": "test@gmail.com"
+	},
+	"payment": {
+		"transaction": "b563feb7b2b84b64c8w",
+		"request_id": "",
+		"currency": "USD",
+		"provider": "wbpay",
+		"amount": 1817,
+		"payment_dt": 1637907727,
+		"bank": "alpha",
+		"delivery_cost": 1500,
+		"goods_total": 317,
+		"custom_fee": 0
+	},
+	"items": [
+		{
+			"chrt_id": 9934930,
+			"track_number": "WBILMTESTTRACK",
+			"price": 453,
+			"rid": "ab4219087a764ae0btest",
+			"name": "Mascaras",
+			"sale": 30,
+			"size": "0",
+			"total_price": 317,
+			"nm_id": 2389212,
+			"brand": "Vivienne Sabo",
+			"status": 202
+		}
+	],
+	"locale": "en",
+	"internal_signature": "",
+	"customer_id": "test",
+	"delivery_service": "meest",
+	"shardkey": "9",
+	"sm_id": 99,
+	"date_created": "2021-11-26T06:22:19Z",
+	"oof_shard": "1"
+}`
+
+func Test_HandleMessage(t *testing.T) {
+	type fields struct {
+		db          *mock_storage.MockStorage
+		cache       *mock_cache.MockCache[string, *domain.Order]
+		deadLetters *mock_service.MockDeadLetterQueue
+		msg         *mock_nats_streaming.MockMessage
+	}
+
+	errDB := errors.New("connection refused")
+
+	test_cases := []struct {
+		test_name string
+		data      []byte
+		attempt   int
+		prepare   func(f *fields)
+	}{
+		{
+			test_name: "Cached and acked after save",
+			data:      []byte(validOrder),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), orderUidMatcher("b563feb7b2b84b64c8w")).Return(nil),
+					f.cache.EXPECT().Add("b563feb7b2b84b64c8w", gomock.Cond(func(x any) bool {
+						return x.(*domain.Order).Status == domain.StatusCreated
+					})).Return(true),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Duplicate is acked",
+			data:      []byte(validOrder),
+			attempt:   2,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), gomock.Any()).Return(storage.ErrEntryAlreadyExists),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Conflict is acked without dead-lettering",
+			data:      []byte(validOrder),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), gomock.Any()).Return(storage.ErrEntryConflict),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Nacked on storage failure",
+			data:      []byte(validOrder),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errDB),
+					f.msg.EXPECT().Nack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Dead-lettered when out of attempts",
+			data:      []byte(validOrder),
+			attempt:   maxAttempts,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errDB),
+					f.deadLetters.EXPECT().Put(gomock.Any(), f.msg, gomock.Any()).Return(nil),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Order failing validation is dead-lettered at once",
+			data:      []byte(strings.Replace(validOrder, `"currency": "USD"`, `"currency": "usd"`, 1)),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.deadLetters.EXPECT().Put(gomock.Any(), f.msg, gomock.Any()).Return(nil),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "New order past created is dead-lettered at once",
+			data:      []byte(strings.Replace(validOrder, `"oof_shard": "1"`, `"oof_shard": "1", "status": "delivered"`, 1)),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.deadLetters.EXPECT().Put(gomock.Any(), f.msg, gomock.Any()).Return(nil),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "New order with created status is saved",
+			data:      []byte(strings.Replace(validOrder, `"oof_shard": "1"`, `"oof_shard": "1", "status": "created"`, 1)),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.db.EXPECT().Save(gomock.Any(), orderUidMatcher("b563feb7b2b84b64c8w")).Return(nil),
+					f.cache.EXPECT().Add("b563feb7b2b84b64c8w", gomock.Any()).Return(true),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Invalid message is dead-lettered at once",
+			data:      []byte(`{"order_uid":`),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.deadLetters.EXPECT().Put(gomock.Any(), f.msg, gomock.Any()).Return(nil),
+					f.msg.EXPECT().Ack().Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Kept unacked when dead-lettering fails",
+			data:      []byte(`{"order_uid":`),
+			attempt:   1,
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.deadLetters.EXPECT().Put(gomock.Any(), f.msg, gomock.Any()).Return(errDB),
+					f.msg.EXPECT().Nack().Return(nil),
+				)
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				db:          mock_storage.NewMockStorage(ctrl),
+				cache:       mock_cache.NewMockCache[string, *domain.Order](ctrl),
+				deadLetters: mock_service.NewMockDeadLetterQueue(ctrl),
+				msg:         mock_nats_streaming.NewMockMessage(ctrl),
+			}
+
+			f.msg.EXPECT().Data().Return(tc.data).AnyTimes()
+			f.msg.EXPECT().Attempt().Return(tc.attempt).AnyTimes()
+			f.msg.EXPECT().Sequence().Return(uint64(1)).AnyTimes()
+			f.msg.EXPECT().Subject().Return("order-notification").AnyTimes()
+
+			tc.prepare(&f)
+
+			svc := service.New(context.Background(), logging.Discard(), f.db, f.cache, f.deadLetters, service.Config{MaxAttempts: maxAttempts})
+			svc.HandleMessage(f.msg)
+		})
+	}
+}
+
+// orderUidMatcher matches a domain.Order by its uid
+type orderUidMatcher string
+
+func (m orderUidMatcher) Matches(x interface{}) bool {
+	order, ok := x.(domain.Order)
+	return ok && order.OrderUid == string(m)
+}
+
+func (m orderUidMatcher) String() string {
+	return "order with uid " + string(m)
+}
+
+func Test_ProcessStatusEvent(t *testing.T) {
+	const orderId = "b563feb7b2b84b64c8w"
+
+	changedAt := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+
+	test_cases := []struct {
+		test_name   string
+		data        string
+		wantErr     error
+		wantEvicted bool
+		prepare     func(db *mock_storage.MockStorage)
+	}{
+		{
+			test_name:   "Allowed transition",
+			data:        `{"order_uid":"b563feb7b2b84b64c8w","status":"paid","changed_at":"2021-11-26T06:22:19Z"}`,
+			wantEvicted: true,
+			prepare: func(db *mock_storage.MockStorage) {
+				gomock.InOrder(
+					db.EXPECT().GetStatus(gomock.Any(), orderId).Return(domain.StatusCreated, nil),
+					db.EXPECT().UpdateStatus(gomock.Any(), domain.StatusChange{
+						OrderUid:  orderId,
+						From:      domain.StatusCreated,
+						To:        domain.StatusPaid,
+						ChangedAt: changedAt,
+					}).Return(nil),
+				)
+			},
+		},
+		{
+			test_name: "Already applied",
+			data:      `{"order_uid":"b563feb7b2b84b64c8w","status":"paid"}`,
+			prepare: func(db *mock_storage.MockStorage) {
+				db.EXPECT().GetStatus(gomock.Any(), orderId).Return(domain.StatusPaid, nil)
+			},
+		},
+		{
+			test_name: "Forbidden transition",
+			data:      `{"order_uid":"b563feb7b2b84b64c8w","status":"delivered"}`,
+			wantErr:   service.ErrInvalidTransition,
+			prepare: func(db *mock_storage.MockStorage) {
+				db.EXPECT().GetStatus(gomock.Any(), orderId).Return(domain.StatusCancelled, nil)
+			},
+		},
+		{
+			test_name: "Unknown status",
+			data:      `{"order_uid":"b563feb7b2b84b64c8w","status":"lost"}`,
+			wantErr:   service.ErrInvalidOrder,
+		},
+		{
+			test_name: "Order not stored yet",
+			data:      `{"order_uid":"b563feb7b2b84b64c8w","status":"paid"}`,
+			wantErr:   storage.ErrEntryDoesntExists,
+			prepare: func(db *mock_storage.MockStorage) {
+				db.EXPECT().GetStatus(gomock.Any(), orderId).Return(domain.OrderStatus(""), storage.ErrEntryDoesntExists)
+			},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db := mock_storage.NewMockStorage(ctrl)
+			if tc.prepare != nil {
+				tc.prepare(db)
+			}
+
+			// the cached order is stale after a status change
+			cache := mock_cache.NewMockCache[string, *domain.Order](ctrl)
+			if tc.wantEvicted {
+				cache.EXPECT().Remove(orderId).Return(true)
+			}
+
+			svc := service.New(context.Background(), logging.Discard(), db, cache, mock_service.NewMockDeadLetterQueue(ctrl), service.Config{MaxAttempts: maxAttempts})
+
+			err := svc.ProcessStatusEvent(context.Background(), []byte(tc.data))
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_RunStopsOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	db := mock_storage.NewMockStorage(ctrl)
+	msg := mock_nats_streaming.NewMockMessage(ctrl)
+
+	msg.EXPECT().Data().Return([]byte(validOrder)).AnyTimes()
+	msg.EXPECT().Sequence().Return(uint64(1)).AnyTimes()
+	msg.EXPECT().Subject().Return("order-notification").AnyTimes()
+
+	// the service is stopped while the message is being saved,
+	// it must still be stored and acked
+	db.EXPECT().Save(gomock.Any(), orderUidMatcher("b563feb7b2b84b64c8w")).
+		DoAndReturn(func(ctx context.Context, _ domain.Order) error {
+			cancel()
+			return ctx.Err()
+		})
+	msg.EXPECT().Ack().Return(nil)
+
+	cache := mock_cache.NewMockCache[string, *domain.Order](ctrl)
+	cache.EXPECT().Add("b563feb7b2b84b64c8w", gomock.Any()).Return(true)
+
+	svc := service.New(ctx, logging.Discard(), db, cache, mock_service.NewMockDeadLetterQueue(ctrl), service.Config{MaxAttempts: maxAttempts})
+
+	msgChan := make(chan nats_streaming.Message, 1)
+	msgChan <- msg
+
+	stopped := make(chan struct{})
+	go func() {
+		svc.Run(msgChan)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context was cancelled")
+	}
+}
+
+func Test_HandleMessageBatched(t *testing.T) {
+	const otherUid = "c563feb7b2b84b64c8w"
+
+	otherOrder := strings.Replace(validOrder, "b563feb7b2b84b64c8w", otherUid, 1)
+
+	test_cases := []struct {
+		test_name string
+		batchSize int
+		data      []string
+		results   []error
+		batchErr  error
+		// errors of the orders saved one by one after the batch failed
+		saveErrs  map[string]error
+		wantAcked []bool
+	}{
+		{
+			test_name: "Flushed by size",
+			batchSize: 2,
+			data:      []string{validOrder, otherOrder},
+			results:   []error{nil, nil},
+			wantAcked: []bool{true, true},
+		},
+		{
+			test_name: "Flushed by timeout",
+			batchSize: 10,
+			data:      []string{validOrder},
+			results:   []error{nil},
+			wantAcked: []bool{true},
+		},
+		{
+			test_name: "Duplicate within batch",
+			batchSize: 2,
+			data:      []string{validOrder, validOrder},
+			results:   []error{nil, storage.ErrEntryAlreadyExists},
+			wantAcked: []bool{true, true},
+		},
+		{
+			test_name: "Batch failed",
+			batchSize: 2,
+			data:      []string{validOrder, otherOrder},
+			batchErr:  errors.New("connection refused"),
+			saveErrs: map[string]error{
+				"b563feb7b2b84b64c8w": errors.New("connection refused"),
+				otherUid:              errors.New("connection refused"),
+			},
+			wantAcked: []bool{false, false},
+		},
+		{
+			test_name: "One order failed the batch",
+			batchSize: 2,
+			data:      []string{validOrder, otherOrder},
+			batchErr:  errors.New("value out of range for type integer"),
+			saveErrs: map[string]error{
+				"b563feb7b2b84b64c8w": nil,
+				otherUid:              errors.New("value out of range for type integer"),
+			},
+			wantAcked: []bool{true, false},
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db := mock_storage.NewMockStorage(ctrl)
+			db.EXPECT().SaveBatch(gomock.Any(), gomock.Len(len(tc.data))).Return(tc.results, tc.batchErr)
+			for uid, err := range tc.saveErrs {
+				db.EXPECT().Save(gomock.Any(), orderUidMatcher(uid)).Return(err)
+			}
+
+			cache := mock_cache.NewMockCache[string, *domain.Order](ctrl)
+			cache.EXPECT().Add(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+			svc := service.New(context.Background(), logging.Discard(), db, cache, mock_service.NewMockDeadLetterQueue(ctrl), service.Config{
+				MaxAttempts:  maxAttempts,
+				BatchSize:    tc.batchSize,
+				BatchTimeout: 10 * time.Millisecond,
+			})
+
+			msgs := make([]*mock_nats_streaming.MockMessage, len(tc.data))
+			for i, data := range tc.data {
+				msg := mock_nats_streaming.NewMockMessage(ctrl)
+				msg.EXPECT().Data().Return([]byte(data)).AnyTimes()
+				msg.EXPECT().Attempt().Return(1).AnyTimes()
+				msg.EXPECT().Sequence().Return(uint64(i + 1)).AnyTimes()
+				msg.EXPECT().Subject().Return("order-notification").AnyTimes()
+				if tc.wantAcked[i] {
+					msg.EXPECT().Ack().Return(nil)
+				} else {
+					msg.EXPECT().Nack().Return(nil)
+				}
+				msgs[i] = msg
+			}
+
+			// each worker waits for its batch to be committed
+			var wg sync.WaitGroup
+			for _, msg := range msgs {
+				wg.Add(1)
+				go func(msg nats_streaming.Message) {
+					defer wg.Done()
+					svc.HandleMessage(msg)
+				}(msg)
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"test-task/order-service/internal/domain"
+	nats_streaming "test-task/order-service/internal/nats-streaming"
+	"time"
+)
+
+var ErrInvalidTransition = fmt.Errorf("%w: status transition is not allowed", ErrInvalidOrder)
+
+// RunStatusEvents handles status change events until msgChan is closed
+func (s *Service) RunStatusEvents(msgChan <-chan nats_streaming.Message) {
+	s.run(msgChan, s.HandleStatusEvent)
+}
+
+// HandleStatusEvent applies the status change, with the same ack semantics as HandleMessage.
+// An event for an order that has not been stored yet is redelivered,
+// since orders and their status events come from different channels
+func (s *Service) HandleStatusEvent(msg nats_streaming.Message) {
+	s.handle(msg, s.ProcessStatusEvent)
+}
+
+func (s *Service) ProcessStatusEvent(ctx context.Context, data []byte) error {
+	const op = "service.ProcessStatusEvent"
+
+	var event domain.StatusEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("%s: failed unmarshalling data: %w: %w", op, ErrInvalidOrder, err)
+	}
+
+	if event.OrderUid == "" || !event.Status.Valid() {
+		return fmt.Errorf("%s: invalid data: %w: order_uid: [%s] status: [%s]", op, ErrInvalidOrder, event.OrderUid, event.Status)
+	}
+
+	if event.ChangedAt.IsZero() {
+		event.ChangedAt = time.Now()
+	}
+
+	current, err := s.db.GetStatus(ctx, event.OrderUid)
+	if err != nil {
+		return fmt.Errorf("%s: getting status: %w", op, err)
+	}
+
+	// redelivered event that has already been applied
+	if current == event.Status {
+		s.log.InfoContext(ctx, "status already applied",
+			slog.String("order_uid", event.OrderUid), slog.String("status", string(current)))
+		return nil
+	}
+
+	if !current.CanTransitionTo(event.Status) {
+		return fmt.Errorf("%s: order: [%s] %s -> %s: %w", op, event.OrderUid, current, event.Status, ErrInvalidTransition)
+	}
+
+	err = s.db.UpdateStatus(ctx, domain.StatusChange{
+		OrderUid:  event.OrderUid,
+		From:      current,
+		To:        event.Status,
+		Reason:    event.Reason,
+		ChangedAt: event.ChangedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: updating status: %w", op, err)
+	}
+
+	// the cached order has the previous status
+	s.cache.Remove(event.OrderUid)
+
+	s.log.InfoContext(ctx, "order status changed", slog.String("order_uid", event.OrderUid),
+		slog.String("from", string(current)), slog.String("to", string(event.Status)))
+
+	return nil
+}
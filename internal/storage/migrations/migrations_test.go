@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadMigrations(t *testing.T) {
+	test_cases := []struct {
+		test_name string
+		files     fstest.MapFS
+		want      []Migration
+		wantErr   bool
+	}{
+		{
+			test_name: "Ordered by version",
+			files: fstest.MapFS{
+				"sql/0010_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+				"sql/0002_create_cache.up.sql":   {Data: []byte("CREATE TABLE cache")},
+				"sql/0002_create_cache.down.sql": {Data: []byte("DROP TABLE cache")},
+			},
+			want: []Migration{
+				{Version: 2, Name: "create_cache", Up: "CREATE TABLE cache", Down: "DROP TABLE cache"},
+				{Version: 10, Name: "add_index", Up: "CREATE INDEX"},
+			},
+		},
+		{
+			test_name: "Missing up script",
+			files: fstest.MapFS{
+				"sql/0001_init.down.sql": {Data: []byte("DROP TABLE orders")},
+			},
+			wantErr: true,
+		},
+		{
+			test_name: "Duplicate version",
+			files: fstest.MapFS{
+				"sql/0001_init.up.sql":  {Data: []byte("CREATE TABLE orders")},
+				"sql/0001_other.up.sql": {Data: []byte("CREATE TABLE other")},
+			},
+			wantErr: true,
+		},
+		{
+			test_name: "Unexpected file name",
+			files: fstest.MapFS{
+				"sql/init.sql": {Data: []byte("CREATE TABLE orders")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			got, err := load(tc.files, "sql")
+
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_EmbeddedMigrations(t *testing.T) {
+	migrations, err := load(embedded, "sql")
+	assert.NoError(t, err)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions must be contiguous")
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down script", m.Version, m.Name)
+	}
+}
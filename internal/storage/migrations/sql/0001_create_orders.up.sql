@@ -0,0 +1,144 @@
+CREATE TABLE IF NOT EXISTS orders (
+	id CHAR(19) PRIMARY KEY,
+	track_number TEXT NOT NULL,
+	entry TEXT NOT NULL,
+	locale TEXT NOT NULL,
+	internal_signature TEXT NOT NULL,
+	customer_id TEXT NOT NULL,
+	delivery_service TEXT NOT NULL,
+	shardkey TEXT NOT NULL,
+	sm_id INTEGER NOT NULL,
+	date_created TIMESTAMPTZ NOT NULL,
+	oof_shard TEXT NOT NULL,
+	data JSONB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+	order_id CHAR(19) PRIMARY KEY REFERENCES orders (id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	zip TEXT NOT NULL,
+	city TEXT NOT NULL,
+	address TEXT NOT NULL,
+	region TEXT NOT NULL,
+	email TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+	order_id CHAR(19) PRIMARY KEY REFERENCES orders (id) ON DELETE CASCADE,
+	transaction TEXT NOT NULL,
+	request_id TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	payment_dt BIGINT NOT NULL,
+	bank TEXT NOT NULL,
+	delivery_cost INTEGER NOT NULL,
+	goods_total INTEGER NOT NULL,
+	custom_fee INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS items (
+	id BIGSERIAL PRIMARY KEY,
+	order_id CHAR(19) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	chrt_id INTEGER NOT NULL,
+	track_number TEXT NOT NULL,
+	price INTEGER NOT NULL,
+	rid TEXT NOT NULL,
+	name TEXT NOT NULL,
+	sale INTEGER NOT NULL,
+	size TEXT NOT NULL,
+	total_price INTEGER NOT NULL,
+	nm_id INTEGER NOT NULL,
+	brand TEXT NOT NULL,
+	status INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS items_order_id_idx ON items (order_id);
+
+-- a database created before the migrations has orders (id, data) only,
+-- the columns are added and filled in from the stored documents
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_id_data_key;
+
+ALTER TABLE orders
+	ADD COLUMN IF NOT EXISTS track_number TEXT,
+	ADD COLUMN IF NOT EXISTS entry TEXT,
+	ADD COLUMN IF NOT EXISTS locale TEXT,
+	ADD COLUMN IF NOT EXISTS internal_signature TEXT,
+	ADD COLUMN IF NOT EXISTS customer_id TEXT,
+	ADD COLUMN IF NOT EXISTS delivery_service TEXT,
+	ADD COLUMN IF NOT EXISTS shardkey TEXT,
+	ADD COLUMN IF NOT EXISTS sm_id INTEGER,
+	ADD COLUMN IF NOT EXISTS date_created TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS oof_shard TEXT;
+
+UPDATE orders SET
+	track_number = COALESCE(data->>'track_number', ''),
+	entry = COALESCE(data->>'entry', ''),
+	locale = COALESCE(data->>'locale', ''),
+	internal_signature = COALESCE(data->>'internal_signature', ''),
+	customer_id = COALESCE(data->>'customer_id', ''),
+	delivery_service = COALESCE(data->>'delivery_service', ''),
+	shardkey = COALESCE(data->>'shardkey', ''),
+	sm_id = COALESCE((data->>'sm_id')::INTEGER, 0),
+	date_created = COALESCE((data->>'date_created')::TIMESTAMPTZ, 'epoch'),
+	oof_shard = COALESCE(data->>'oof_shard', '')
+WHERE track_number IS NULL;
+
+ALTER TABLE orders
+	ALTER COLUMN track_number SET NOT NULL,
+	ALTER COLUMN entry SET NOT NULL,
+	ALTER COLUMN locale SET NOT NULL,
+	ALTER COLUMN internal_signature SET NOT NULL,
+	ALTER COLUMN customer_id SET NOT NULL,
+	ALTER COLUMN delivery_service SET NOT NULL,
+	ALTER COLUMN shardkey SET NOT NULL,
+	ALTER COLUMN sm_id SET NOT NULL,
+	ALTER COLUMN date_created SET NOT NULL,
+	ALTER COLUMN oof_shard SET NOT NULL;
+
+INSERT INTO deliveries (order_id, name, phone, zip, city, address, region, email)
+SELECT id,
+	COALESCE(data->'delivery'->>'name', ''),
+	COALESCE(data->'delivery'->>'phone', ''),
+	COALESCE(data->'delivery'->>'zip', ''),
+	COALESCE(data->'delivery'->>'city', ''),
+	COALESCE(data->'delivery'->>'address', ''),
+	COALESCE(data->'delivery'->>'region', ''),
+	COALESCE(data->'delivery'->>'email', '')
+FROM orders
+ON CONFLICT (order_id) DO NOTHING;
+
+INSERT INTO payments (order_id, transaction, request_id, currency, provider, amount,
+	payment_dt, bank, delivery_cost, goods_total, custom_fee)
+SELECT id,
+	COALESCE(data->'payment'->>'transaction', ''),
+	COALESCE(data->'payment'->>'request_id', ''),
+	COALESCE(data->'payment'->>'currency', ''),
+	COALESCE(data->'payment'->>'provider', ''),
+	COALESCE((data->'payment'->>'amount')::INTEGER, 0),
+	COALESCE((data->'payment'->>'payment_dt')::BIGINT, 0),
+	COALESCE(data->'payment'->>'bank', ''),
+	COALESCE((data->'payment'->>'delivery_cost')::INTEGER, 0),
+	COALESCE((data->'payment'->>'goods_total')::INTEGER, 0),
+	COALESCE((data->'payment'->>'custom_fee')::INTEGER, 0)
+FROM orders
+ON CONFLICT (order_id) DO NOTHING;
+
+INSERT INTO items (order_id, chrt_id, track_number, price, rid, name, sale,
+	size, total_price, nm_id, brand, status)
+SELECT o.id,
+	COALESCE((i->>'chrt_id')::INTEGER, 0),
+	COALESCE(i->>'track_number', ''),
+	COALESCE((i->>'price')::INTEGER, 0),
+	COALESCE(i->>'rid', ''),
+	COALESCE(i->>'name', ''),
+	COALESCE((i->>'sale')::INTEGER, 0),
+	COALESCE(i->>'size', ''),
+	COALESCE((i->>'total_price')::INTEGER, 0),
+	COALESCE((i->>'nm_id')::INTEGER, 0),
+	COALESCE(i->>'brand', ''),
+	COALESCE((i->>'status')::INTEGER, 0)
+FROM orders o
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(o.data->'items', '[]'::JSONB)) AS i
+WHERE NOT EXISTS (SELECT 1 FROM items WHERE items.order_id = o.id);
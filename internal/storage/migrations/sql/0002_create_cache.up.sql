@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS cache (
+	id CHAR(19) PRIMARY KEY,
+	data JSONB NOT NULL,
+	UNIQUE (id, data)
+);
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS orders_date_created_id_idx ON orders (date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id, date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS orders_track_number_idx ON orders (track_number);
+CREATE INDEX IF NOT EXISTS orders_delivery_service_idx ON orders (delivery_service, date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS payments_provider_idx ON payments (provider);
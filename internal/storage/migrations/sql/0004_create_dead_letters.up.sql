@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS dead_letters (
+	id BIGSERIAL PRIMARY KEY,
+	channel TEXT NOT NULL,
+	sequence BIGINT NOT NULL,
+	attempts INTEGER NOT NULL,
+	reason TEXT NOT NULL,
+	data BYTEA NOT NULL,
+	failed_at TIMESTAMPTZ NOT NULL
+);
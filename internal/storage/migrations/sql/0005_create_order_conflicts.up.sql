@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS order_conflicts (
+	id BIGSERIAL PRIMARY KEY,
+	order_id CHAR(19) NOT NULL,
+	data JSONB NOT NULL,
+	received_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_conflicts_order_id_idx ON order_conflicts (order_id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS order_access_stats (
+	order_id CHAR(19) PRIMARY KEY,
+	hits BIGINT NOT NULL,
+	last_access TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_access_stats_hits_idx ON order_access_stats (hits DESC);
@@ -0,0 +1 @@
+ALTER TABLE dead_letters DROP CONSTRAINT IF EXISTS dead_letters_channel_sequence_key;
@@ -0,0 +1,5 @@
+DELETE FROM dead_letters d USING dead_letters o
+WHERE d.channel = o.channel AND d.sequence = o.sequence AND d.id > o.id;
+
+ALTER TABLE dead_letters DROP CONSTRAINT IF EXISTS dead_letters_channel_sequence_key;
+ALTER TABLE dead_letters ADD CONSTRAINT dead_letters_channel_sequence_key UNIQUE (channel, sequence);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS order_access_stats_last_access_idx;
@@ -0,0 +1,4 @@
+DELETE FROM order_access_stats s
+WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = s.order_id);
+
+CREATE INDEX IF NOT EXISTS order_access_stats_last_access_idx ON order_access_stats (last_access);
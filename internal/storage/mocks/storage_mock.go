@@ -0,0 +1,375 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/storage/storage.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/storage/storage.go -destination=internal/storage/mocks/storage_mock.go
+//
+
+// Package mock_storage is a generated GoMock package.
+package mock_storage
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "test-task/order-service/internal/domain"
+	storage "test-task/order-service/internal/storage"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockStorage) Get(ctx context.Context, orderId string) (*domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, orderId)
+	ret0, _ := ret[0].(*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStorageMockRecorder) Get(ctx, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStorage)(nil).Get), ctx, orderId)
+}
+
+// GetStatus mocks base method.
+func (m *MockStorage) GetStatus(ctx context.Context, orderId string) (domain.OrderStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", ctx, orderId)
+	ret0, _ := ret[0].(domain.OrderStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockStorageMockRecorder) GetStatus(ctx, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockStorage)(nil).GetStatus), ctx, orderId)
+}
+
+// List mocks base method.
+func (m *MockStorage) List(ctx context.Context, filter storage.ListFilter) (*storage.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].(*storage.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStorageMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStorage)(nil).List), ctx, filter)
+}
+
+// Save mocks base method.
+func (m *MockStorage) Save(ctx context.Context, order domain.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockStorageMockRecorder) Save(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockStorage)(nil).Save), ctx, order)
+}
+
+// SaveBatch mocks base method.
+func (m *MockStorage) SaveBatch(ctx context.Context, orders []domain.Order) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, orders)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockStorageMockRecorder) SaveBatch(ctx, orders any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockStorage)(nil).SaveBatch), ctx, orders)
+}
+
+// StatusHistory mocks base method.
+func (m *MockStorage) StatusHistory(ctx context.Context, orderId string) ([]domain.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusHistory", ctx, orderId)
+	ret0, _ := ret[0].([]domain.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusHistory indicates an expected call of StatusHistory.
+func (mr *MockStorageMockRecorder) StatusHistory(ctx, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusHistory", reflect.TypeOf((*MockStorage)(nil).StatusHistory), ctx, orderId)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockStorage) UpdateStatus(ctx context.Context, change domain.StatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockStorageMockRecorder) UpdateStatus(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockStorage)(nil).UpdateStatus), ctx, change)
+}
+
+// MockDeadLetterStorage is a mock of DeadLetterStorage interface.
+type MockDeadLetterStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterStorageMockRecorder
+}
+
+// MockDeadLetterStorageMockRecorder is the mock recorder for MockDeadLetterStorage.
+type MockDeadLetterStorageMockRecorder struct {
+	mock *MockDeadLetterStorage
+}
+
+// NewMockDeadLetterStorage creates a new mock instance.
+func NewMockDeadLetterStorage(ctrl *gomock.Controller) *MockDeadLetterStorage {
+	mock := &MockDeadLetterStorage{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterStorage) EXPECT() *MockDeadLetterStorageMockRecorder {
+	return m.recorder
+}
+
+// DeleteDeadLetter mocks base method.
+func (m *MockDeadLetterStorage) DeleteDeadLetter(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeadLetter", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeadLetter indicates an expected call of DeleteDeadLetter.
+func (mr *MockDeadLetterStorageMockRecorder) DeleteDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeadLetter", reflect.TypeOf((*MockDeadLetterStorage)(nil).DeleteDeadLetter), ctx, id)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockDeadLetterStorage) GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(*domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockDeadLetterStorageMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockDeadLetterStorage)(nil).GetDeadLetter), ctx, id)
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockDeadLetterStorage) ListDeadLetters(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, afterId, limit)
+	ret0, _ := ret[0].([]domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockDeadLetterStorageMockRecorder) ListDeadLetters(ctx, afterId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockDeadLetterStorage)(nil).ListDeadLetters), ctx, afterId, limit)
+}
+
+// SaveDeadLetter mocks base method.
+func (m *MockDeadLetterStorage) SaveDeadLetter(ctx context.Context, dl *domain.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetter", ctx, dl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetter indicates an expected call of SaveDeadLetter.
+func (mr *MockDeadLetterStorageMockRecorder) SaveDeadLetter(ctx, dl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetter", reflect.TypeOf((*MockDeadLetterStorage)(nil).SaveDeadLetter), ctx, dl)
+}
+
+// MockAccessStatsStorage is a mock of AccessStatsStorage interface.
+type MockAccessStatsStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessStatsStorageMockRecorder
+}
+
+// MockAccessStatsStorageMockRecorder is the mock recorder for MockAccessStatsStorage.
+type MockAccessStatsStorageMockRecorder struct {
+	mock *MockAccessStatsStorage
+}
+
+// NewMockAccessStatsStorage creates a new mock instance.
+func NewMockAccessStatsStorage(ctrl *gomock.Controller) *MockAccessStatsStorage {
+	mock := &MockAccessStatsStorage{ctrl: ctrl}
+	mock.recorder = &MockAccessStatsStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessStatsStorage) EXPECT() *MockAccessStatsStorageMockRecorder {
+	return m.recorder
+}
+
+// MostAccessed mocks base method.
+func (m *MockAccessStatsStorage) MostAccessed(ctx context.Context, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MostAccessed", ctx, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MostAccessed indicates an expected call of MostAccessed.
+func (mr *MockAccessStatsStorageMockRecorder) MostAccessed(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MostAccessed", reflect.TypeOf((*MockAccessStatsStorage)(nil).MostAccessed), ctx, limit)
+}
+
+// PurgeAccesses mocks base method.
+func (m *MockAccessStatsStorage) PurgeAccesses(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAccesses", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeAccesses indicates an expected call of PurgeAccesses.
+func (mr *MockAccessStatsStorageMockRecorder) PurgeAccesses(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAccesses", reflect.TypeOf((*MockAccessStatsStorage)(nil).PurgeAccesses), ctx, olderThan)
+}
+
+// RecordAccesses mocks base method.
+func (m *MockAccessStatsStorage) RecordAccesses(ctx context.Context, counts map[string]int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAccesses", ctx, counts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAccesses indicates an expected call of RecordAccesses.
+func (mr *MockAccessStatsStorageMockRecorder) RecordAccesses(ctx, counts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAccesses", reflect.TypeOf((*MockAccessStatsStorage)(nil).RecordAccesses), ctx, counts)
+}
+
+// MockIdempotencyStorage is a mock of IdempotencyStorage interface.
+type MockIdempotencyStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyStorageMockRecorder
+}
+
+// MockIdempotencyStorageMockRecorder is the mock recorder for MockIdempotencyStorage.
+type MockIdempotencyStorageMockRecorder struct {
+	mock *MockIdempotencyStorage
+}
+
+// NewMockIdempotencyStorage creates a new mock instance.
+func NewMockIdempotencyStorage(ctrl *gomock.Controller) *MockIdempotencyStorage {
+	mock := &MockIdempotencyStorage{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyStorage) EXPECT() *MockIdempotencyStorageMockRecorder {
+	return m.recorder
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockIdempotencyStorage) ClaimIdempotencyKey(ctx context.Context, key, requestHash string, staleAfter time.Duration) (*domain.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, key, requestHash, staleAfter)
+	ret0, _ := ret[0].(*domain.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockIdempotencyStorageMockRecorder) ClaimIdempotencyKey(ctx, key, requestHash, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockIdempotencyStorage)(nil).ClaimIdempotencyKey), ctx, key, requestHash, staleAfter)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockIdempotencyStorage) CompleteIdempotencyKey(ctx context.Context, key, orderId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, key, orderId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockIdempotencyStorageMockRecorder) CompleteIdempotencyKey(ctx, key, orderId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockIdempotencyStorage)(nil).CompleteIdempotencyKey), ctx, key, orderId)
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockIdempotencyStorage) PurgeIdempotencyKeys(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockIdempotencyStorageMockRecorder) PurgeIdempotencyKeys(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockIdempotencyStorage)(nil).PurgeIdempotencyKeys), ctx, olderThan)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockIdempotencyStorage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockIdempotencyStorageMockRecorder) ReleaseIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockIdempotencyStorage)(nil).ReleaseIdempotencyKey), ctx, key)
+}
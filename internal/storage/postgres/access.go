@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test-task/order-service/internal/metrics"
+	"time"
+)
+
+const (
+	qRecordAccesses = `INSERT INTO order_access_stats (order_id, hits, last_access)
+		SELECT s.id, s.hits, now() FROM unnest($1::text[], $2::bigint[]) AS s (id, hits)
+		JOIN orders o ON o.id = s.id
+		ON CONFLICT (order_id) DO UPDATE SET
+			hits = order_access_stats.hits + EXCLUDED.hits,
+			last_access = EXCLUDED.last_access`
+
+	qMostAccessed = `SELECT order_id FROM order_access_stats ORDER BY hits DESC LIMIT $1`
+
+	qPurgeAccesses = `DELETE FROM order_access_stats WHERE last_access < now() - make_interval(secs => $1)`
+)
+
+// RecordAccesses adds the lookup counts of the orders to their totals,
+// counts of orders that are not stored are dropped
+func (s *Storage) RecordAccesses(ctx context.Context, counts map[string]int64) error {
+	const op = "storage.postgres.RecordAccesses"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(counts))
+	hits := make([]int64, 0, len(counts))
+	for id, n := range counts {
+		ids = append(ids, id)
+		hits = append(hits, n)
+	}
+
+	if _, err := s.db.ExecContext(ctx, qRecordAccesses, ids, hits); err != nil {
+		return fmt.Errorf("%s: upserting stats: %w", op, err)
+	}
+
+	return nil
+}
+
+// MostAccessed returns the ids of the orders looked up most often
+func (s *Storage) MostAccessed(ctx context.Context, limit int) ([]string, error) {
+	const op = "storage.postgres.MostAccessed"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var ids []string
+	if err := s.db.SelectContext(ctx, &ids, qMostAccessed, limit); err != nil {
+		return nil, fmt.Errorf("%s: selecting stats: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// PurgeAccesses deletes the stats of the orders not looked up for olderThan
+func (s *Storage) PurgeAccesses(ctx context.Context, olderThan time.Duration) (int64, error) {
+	const op = "storage.postgres.PurgeAccesses"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	res, err := s.db.ExecContext(ctx, qPurgeAccesses, olderThan.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("%s: deleting stats: %w", op, err)
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: deleting stats: %w", op, err)
+	}
+
+	return purged, nil
+}
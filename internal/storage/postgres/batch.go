@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+// maxBindParams is the limit of bind parameters in a single statement
+const maxBindParams = 65535
+
+const (
+	qBulkOrders = `INSERT INTO orders (
+		id, track_number, entry, locale, internal_signature, customer_id,
+		delivery_service, shardkey, sm_id, date_created, oof_shard, status, data
+	)`
+
+	qBulkDeliveries = `INSERT INTO deliveries (
+		order_id, name, phone, zip, city, address, region, email
+	)`
+
+	qBulkPayments = `INSERT INTO payments (
+		order_id, transaction, request_id, currency, provider, amount,
+		payment_dt, bank, delivery_cost, goods_total, custom_fee
+	)`
+
+	qBulkItems = `INSERT INTO items (
+		order_id, chrt_id, track_number, price, rid, name,
+		sale, size, total_price, nm_id, brand, status
+	)`
+
+	qBulkStatusChanges = `INSERT INTO order_status_history (
+		order_id, from_status, to_status, reason, changed_at
+	)`
+)
+
+// SaveBatch saves the orders in one transaction using multi-row inserts.
+// The result holds an error for each order with the same meaning as in Save:
+// nil when the order has been inserted, storage.ErrEntryAlreadyExists or
+// storage.ErrEntryConflict for a duplicate, including duplicates within the batch.
+// The returned error means nothing has been saved
+func (s *Storage) SaveBatch(ctx context.Context, orders []domain.Order) (results []error, err error) {
+	const op = "storage.postgres.SaveBatch"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	results = make([]error, len(orders))
+	if len(orders) == 0 {
+		return results, nil
+	}
+
+	data := make([][]byte, len(orders))
+	// index of the first order with the uid, the others are duplicates
+	first := make(map[string]int, len(orders))
+
+	var orderRows [][]any
+
+	for i, order := range orders {
+		if data[i], err = json.Marshal(order); err != nil {
+			return nil, fmt.Errorf("%s: marshalling order: %w", op, err)
+		}
+
+		if _, ok := first[order.OrderUid]; ok {
+			continue
+		}
+		first[order.OrderUid] = i
+
+		row := newOrderRow(order)
+		if row.Status == "" {
+			row.Status = string(domain.StatusCreated)
+		}
+		orderRows = append(orderRows, row.values(data[i]))
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	inserted := make(map[string]bool, len(orderRows))
+
+	err = bulk(qBulkOrders, orderRows, func(q string, args []any) error {
+		var ids []string
+		if err := tx.SelectContext(ctx, &ids, q+" ON CONFLICT (id) DO NOTHING RETURNING id", args...); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			inserted[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: saving orders: %w", op, err)
+	}
+
+	var deliveries, payments, items, statuses [][]any
+
+	for i, order := range orders {
+		if first[order.OrderUid] != i || !inserted[order.OrderUid] {
+			results[i] = s.resolveDuplicate(ctx, tx, order.OrderUid, data[i])
+			if !errors.Is(results[i], storage.ErrEntryAlreadyExists) {
+				err = results[i]
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+
+		status := order.Status
+		if status == "" {
+			status = domain.StatusCreated
+		}
+
+		deliveries = append(deliveries, newDeliveryRow(order.OrderUid, order.Delivery).values())
+		payments = append(payments, newPaymentRow(order.OrderUid, order.Payment).values())
+		for _, item := range order.Items {
+			items = append(items, newItemRow(order.OrderUid, item).values())
+		}
+		statuses = append(statuses, []any{order.OrderUid, nil, string(status), "", order.DateCreated})
+	}
+
+	exec := func(q string, args []any) error {
+		_, err := tx.ExecContext(ctx, q, args...)
+		return err
+	}
+
+	if err = bulk(qBulkDeliveries, deliveries, exec); err != nil {
+		return nil, fmt.Errorf("%s: saving deliveries: %w", op, err)
+	}
+	if err = bulk(qBulkPayments, payments, exec); err != nil {
+		return nil, fmt.Errorf("%s: saving payments: %w", op, err)
+	}
+	if err = bulk(qBulkItems, items, exec); err != nil {
+		return nil, fmt.Errorf("%s: saving items: %w", op, err)
+	}
+	if err = bulk(qBulkStatusChanges, statuses, exec); err != nil {
+		return nil, fmt.Errorf("%s: saving initial statuses: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	s.log.DebugContext(ctx, "orders batch saved", slog.String("op", op),
+		slog.Int("size", len(orders)), slog.Int("inserted", len(inserted)))
+
+	return results, nil
+}
+
+// bulk runs fn with multi-row VALUES appended to head, splitting
+// the rows so every statement stays within maxBindParams
+func bulk(head string, rows [][]any, fn func(q string, args []any) error) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	cols := len(rows[0])
+	perStmt := maxBindParams / cols
+
+	for start := 0; start < len(rows); start += perStmt {
+		end := min(start+perStmt, len(rows))
+
+		var b strings.Builder
+		b.WriteString(head)
+		b.WriteString(" VALUES ")
+
+		args := make([]any, 0, (end-start)*cols)
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			for j, v := range row {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				args = append(args, v)
+				b.WriteByte('$')
+				b.WriteString(strconv.Itoa(len(args)))
+			}
+			b.WriteByte(')')
+		}
+
+		if err := fn(b.String(), args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Bulk(t *testing.T) {
+	rows := make([][]any, 0, 3)
+	for i := 0; i < 3; i++ {
+		rows = append(rows, []any{i, "x"})
+	}
+
+	var (
+		queries []string
+		args    [][]any
+	)
+
+	err := bulk("INSERT INTO t (a, b)", rows, func(q string, a []any) error {
+		queries = append(queries, q)
+		args = append(args, a)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4), ($5, $6)"}, queries)
+	assert.Equal(t, [][]any{{0, "x", 1, "x", 2, "x"}}, args)
+}
+
+func Test_BulkSplitsByBindParams(t *testing.T) {
+	const cols = 13
+
+	rows := make([][]any, maxBindParams/cols+1)
+	for i := range rows {
+		rows[i] = make([]any, cols)
+	}
+
+	var stmts []int
+
+	err := bulk("INSERT INTO orders", rows, func(q string, a []any) error {
+		stmts = append(stmts, len(a)/cols)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{maxBindParams / cols, 1}, stmts)
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+const (
+	qInsertDeadLetter = `INSERT INTO dead_letters (channel, sequence, attempts, reason, data, failed_at)
+		VALUES (:channel, :sequence, :attempts, :reason, :data, :failed_at)
+		ON CONFLICT (channel, sequence) DO NOTHING RETURNING id`
+
+	qSelectDeadLetterId = `SELECT id FROM dead_letters WHERE channel = $1 AND sequence = $2`
+
+	qListDeadLetters = `SELECT id, channel, sequence, attempts, reason, data, failed_at
+		FROM dead_letters WHERE id > $1 ORDER BY id LIMIT $2`
+
+	qSelectDeadLetter = `SELECT id, channel, sequence, attempts, reason, data, failed_at
+		FROM dead_letters WHERE id = $1`
+
+	qDeleteDeadLetter = `DELETE FROM dead_letters WHERE id = $1`
+)
+
+// SaveDeadLetter stores the dead letter and sets its id. A message stored
+// already returns storage.ErrEntryAlreadyExists, with the id of the stored one
+func (s *Storage) SaveDeadLetter(ctx context.Context, dl *domain.DeadLetter) error {
+	const op = "storage.postgres.SaveDeadLetter"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	stmt, err := s.db.PrepareNamedContext(ctx, qInsertDeadLetter)
+	if err != nil {
+		return fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	err = stmt.GetContext(ctx, &dl.Id, newDeadLetterRow(dl))
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := s.db.GetContext(ctx, &dl.Id, qSelectDeadLetterId, dl.Channel, int64(dl.Sequence)); err != nil {
+			return fmt.Errorf("%s: selecting stored entry: %w", op, err)
+		}
+		return fmt.Errorf("%s: %w", op, storage.ErrEntryAlreadyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: saving entry: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListDeadLetters(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error) {
+	const op = "storage.postgres.ListDeadLetters"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var rows []deadLetterRow
+	if err := s.db.SelectContext(ctx, &rows, qListDeadLetters, afterId, limit); err != nil {
+		return nil, fmt.Errorf("%s: selecting entries: %w", op, err)
+	}
+
+	res := make([]domain.DeadLetter, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, r.toDomain())
+	}
+
+	return res, nil
+}
+
+func (s *Storage) GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	const op = "storage.postgres.GetDeadLetter"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var row deadLetterRow
+
+	err := s.db.GetContext(ctx, &row, qSelectDeadLetter, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrEntryDoesntExists
+		}
+
+		return nil, fmt.Errorf("%s: selecting entry: %w", op, err)
+	}
+
+	dl := row.toDomain()
+	return &dl, nil
+}
+
+func (s *Storage) DeleteDeadLetter(ctx context.Context, id int64) error {
+	const op = "storage.postgres.DeleteDeadLetter"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	res, err := s.db.ExecContext(ctx, qDeleteDeadLetter, id)
+	if err != nil {
+		return fmt.Errorf("%s: deleting entry: %w", op, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return storage.ErrEntryDoesntExists
+	}
+
+	return nil
+}
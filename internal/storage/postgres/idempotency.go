@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+const (
+	// a claim left in progress by a crashed instance is taken over once stale
+	qClaimIdempotencyKey = `INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, created_at = now()
+		WHERE idempotency_keys.order_id IS NULL
+			AND idempotency_keys.created_at < now() - make_interval(secs => $3)`
+
+	qSelectIdempotencyKey = `SELECT key, request_hash, order_id, created_at
+		FROM idempotency_keys WHERE key = $1`
+
+	qCompleteIdempotencyKey = `UPDATE idempotency_keys SET order_id = $2 WHERE key = $1`
+
+	qReleaseIdempotencyKey = `DELETE FROM idempotency_keys WHERE key = $1 AND order_id IS NULL`
+
+	qPurgeIdempotencyKeys = `DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)`
+)
+
+// ClaimIdempotencyKey records the key, unless it is recorded already,
+// in which case the recorded key is returned with storage.ErrEntryAlreadyExists.
+// A key still in progress after staleAfter is claimed again
+func (s *Storage) ClaimIdempotencyKey(ctx context.Context, key string, requestHash string, staleAfter time.Duration) (*domain.IdempotencyKey, error) {
+	const op = "storage.postgres.ClaimIdempotencyKey"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	res, err := s.db.ExecContext(ctx, qClaimIdempotencyKey, key, requestHash, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: inserting key: %w", op, err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%s: inserting key: %w", op, err)
+	}
+
+	if inserted == 1 {
+		return nil, nil
+	}
+
+	var row idempotencyKeyRow
+	if err := s.db.GetContext(ctx, &row, qSelectIdempotencyKey, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// released in the meantime, the request may be retried
+			return nil, fmt.Errorf("%s: key released concurrently", op)
+		}
+		return nil, fmt.Errorf("%s: selecting key: %w", op, err)
+	}
+
+	recorded := row.toDomain()
+	return &recorded, storage.ErrEntryAlreadyExists
+}
+
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string, orderId string) error {
+	const op = "storage.postgres.CompleteIdempotencyKey"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if _, err := s.db.ExecContext(ctx, qCompleteIdempotencyKey, key, orderId); err != nil {
+		return fmt.Errorf("%s: updating key: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the key unless its request has completed
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage.postgres.ReleaseIdempotencyKey"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if _, err := s.db.ExecContext(ctx, qReleaseIdempotencyKey, key); err != nil {
+		return fmt.Errorf("%s: deleting key: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes the keys claimed more than olderThan ago
+func (s *Storage) PurgeIdempotencyKeys(ctx context.Context, olderThan time.Duration) (int64, error) {
+	const op = "storage.postgres.PurgeIdempotencyKeys"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	res, err := s.db.ExecContext(ctx, qPurgeIdempotencyKeys, olderThan.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("%s: deleting keys: %w", op, err)
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: deleting keys: %w", op, err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"test-task/order-service/internal/domain"
+)
+
+type orderRow struct {
+	Id                string    `db:"id"`
+	TrackNumber       string    `db:"track_number"`
+	Entry             string    `db:"entry"`
+	Locale            string    `db:"locale"`
+	InternalSignature string    `db:"internal_signature"`
+	CustomerId        string    `db:"customer_id"`
+	DeliveryService   string    `db:"delivery_service"`
+	Shardkey          string    `db:"shardkey"`
+	SmId              int       `db:"sm_id"`
+	DateCreated       time.Time `db:"date_created"`
+	OofShard          string    `db:"oof_shard"`
+	Status            string    `db:"status"`
+}
+
+type deliveryRow struct {
+	OrderId string `db:"order_id"`
+	Name    string `db:"name"`
+	Phone   string `db:"phone"`
+	Zip     string `db:"zip"`
+	City    string `db:"city"`
+	Address string `db:"address"`
+	Region  string `db:"region"`
+	Email   string `db:"email"`
+}
+
+type paymentRow struct {
+	OrderId      string `db:"order_id"`
+	Transaction  string `db:"transaction"`
+	RequestId    string `db:"request_id"`
+	Currency     string `db:"currency"`
+	Provider     string `db:"provider"`
+	Amount       int    `db:"amount"`
+	PaymentDt    int    `db:"payment_dt"`
+	Bank         string `db:"bank"`
+	DeliveryCost int    `db:"delivery_cost"`
+	GoodsTotal   int    `db:"goods_total"`
+	CustomFee    int    `db:"custom_fee"`
+}
+
+type itemRow struct {
+	OrderId     string `db:"order_id"`
+	ChrtId      int    `db:"chrt_id"`
+	TrackNumber string `db:"track_number"`
+	Price       int    `db:"price"`
+	Rid         string `db:"rid"`
+	Name        string `db:"name"`
+	Sale        int    `db:"sale"`
+	Size        string `db:"size"`
+	TotalPrice  int    `db:"total_price"`
+	NmId        int    `db:"nm_id"`
+	Brand       string `db:"brand"`
+	Status      int    `db:"status"`
+}
+
+type deadLetterRow struct {
+	Id       int64     `db:"id"`
+	Channel  string    `db:"channel"`
+	Sequence int64     `db:"sequence"`
+	Attempts int       `db:"attempts"`
+	Reason   string    `db:"reason"`
+	Data     []byte    `db:"data"`
+	FailedAt time.Time `db:"failed_at"`
+}
+
+type idempotencyKeyRow struct {
+	Key         string         `db:"key"`
+	RequestHash string         `db:"request_hash"`
+	OrderId     sql.NullString `db:"order_id"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+type statusChangeRow struct {
+	OrderId   string    `db:"order_id"`
+	From      string    `db:"from_status"`
+	To        string    `db:"to_status"`
+	Reason    string    `db:"reason"`
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+func newOrderRow(o domain.Order) orderRow {
+	return orderRow{
+		Id:                o.OrderUid,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerId,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmId:              o.SmId,
+		DateCreated:       o.DateCreated,
+		OofShard:          o.OofShard,
+		Status:            string(o.Status),
+	}
+}
+
+func newDeliveryRow(orderId string, d domain.Delivery) deliveryRow {
+	return deliveryRow{
+		OrderId: orderId,
+		Name:    d.Name,
+		Phone:   d.Phone,
+		Zip:     d.Zip,
+		City:    d.City,
+		Address: d.Address,
+		Region:  d.Region,
+		Email:   d.Email,
+	}
+}
+
+func newPaymentRow(orderId string, p domain.Payment) paymentRow {
+	return paymentRow{
+		OrderId:      orderId,
+		Transaction:  p.Transaction,
+		RequestId:    p.RequestId,
+		Currency:     p.Currency,
+		Provider:     p.Provider,
+		Amount:       p.Amount,
+		PaymentDt:    p.PaymentDt,
+		Bank:         p.Bank,
+		DeliveryCost: p.DeliveryCost,
+		GoodsTotal:   p.GoodsTotal,
+		CustomFee:    p.CustomFee,
+	}
+}
+
+func newItemRow(orderId string, i domain.Item) itemRow {
+	return itemRow{
+		OrderId:     orderId,
+		ChrtId:      i.ChrtId,
+		TrackNumber: i.TrackNumber,
+		Price:       i.Price,
+		Rid:         i.Rid,
+		Name:        i.Name,
+		Sale:        i.Sale,
+		Size:        i.Size,
+		TotalPrice:  i.TotalPrice,
+		NmId:        i.NmId,
+		Brand:       i.Brand,
+		Status:      i.Status,
+	}
+}
+
+// values returns the columns in the order of qBulkOrders
+func (r orderRow) values(data []byte) []any {
+	return []any{
+		r.Id, r.TrackNumber, r.Entry, r.Locale, r.InternalSignature, r.CustomerId,
+		r.DeliveryService, r.Shardkey, r.SmId, r.DateCreated, r.OofShard, r.Status, data,
+	}
+}
+
+func (r deliveryRow) values() []any {
+	return []any{r.OrderId, r.Name, r.Phone, r.Zip, r.City, r.Address, r.Region, r.Email}
+}
+
+func (r paymentRow) values() []any {
+	return []any{
+		r.OrderId, r.Transaction, r.RequestId, r.Currency, r.Provider, r.Amount,
+		r.PaymentDt, r.Bank, r.DeliveryCost, r.GoodsTotal, r.CustomFee,
+	}
+}
+
+func (r itemRow) values() []any {
+	return []any{
+		r.OrderId, r.ChrtId, r.TrackNumber, r.Price, r.Rid, r.Name,
+		r.Sale, r.Size, r.TotalPrice, r.NmId, r.Brand, r.Status,
+	}
+}
+
+// toDomain assembles an order from its normalized rows
+func (r orderRow) toDomain(d deliveryRow, p paymentRow, items []itemRow) *domain.Order {
+	order := &domain.Order{
+		OrderUid:          r.Id,
+		TrackNumber:       r.TrackNumber,
+		Entry:             r.Entry,
+		Locale:            r.Locale,
+		InternalSignature: r.InternalSignature,
+		CustomerId:        r.CustomerId,
+		DeliveryService:   r.DeliveryService,
+		Shardkey:          r.Shardkey,
+		SmId:              r.SmId,
+		DateCreated:       r.DateCreated,
+		OofShard:          r.OofShard,
+		Status:            domain.OrderStatus(r.Status),
+		Delivery: domain.Delivery{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Email:   d.Email,
+		},
+		Payment: domain.Payment{
+			Transaction:  p.Transaction,
+			RequestId:    p.RequestId,
+			Currency:     p.Currency,
+			Provider:     p.Provider,
+			Amount:       p.Amount,
+			PaymentDt:    p.PaymentDt,
+			Bank:         p.Bank,
+			DeliveryCost: p.DeliveryCost,
+			GoodsTotal:   p.GoodsTotal,
+			CustomFee:    p.CustomFee,
+		},
+		Items: make([]domain.Item, 0, len(items)),
+	}
+
+	for _, i := range items {
+		order.Items = append(order.Items, domain.Item{
+			ChrtId:      i.ChrtId,
+			TrackNumber: i.TrackNumber,
+			Price:       i.Price,
+			Rid:         i.Rid,
+			Name:        i.Name,
+			Sale:        i.Sale,
+			Size:        i.Size,
+			TotalPrice:  i.TotalPrice,
+			NmId:        i.NmId,
+			Brand:       i.Brand,
+			Status:      i.Status,
+		})
+	}
+
+	return order
+}
+
+func newDeadLetterRow(dl *domain.DeadLetter) deadLetterRow {
+	return deadLetterRow{
+		Id:       dl.Id,
+		Channel:  dl.Channel,
+		Sequence: int64(dl.Sequence),
+		Attempts: dl.Attempts,
+		Reason:   dl.Reason,
+		Data:     dl.Data,
+		FailedAt: dl.FailedAt,
+	}
+}
+
+func (r deadLetterRow) toDomain() domain.DeadLetter {
+	return domain.DeadLetter{
+		Id:       r.Id,
+		Channel:  r.Channel,
+		Sequence: uint64(r.Sequence),
+		Attempts: r.Attempts,
+		Reason:   r.Reason,
+		Data:     r.Data,
+		FailedAt: r.FailedAt,
+	}
+}
+
+func (r statusChangeRow) toDomain() domain.StatusChange {
+	return domain.StatusChange{
+		OrderUid:  r.OrderId,
+		From:      domain.OrderStatus(r.From),
+		To:        domain.OrderStatus(r.To),
+		Reason:    r.Reason,
+		ChangedAt: r.ChangedAt,
+	}
+}
+
+func (r idempotencyKeyRow) toDomain() domain.IdempotencyKey {
+	return domain.IdempotencyKey{
+		Key:         r.Key,
+		RequestHash: r.RequestHash,
+		OrderUid:    r.OrderId.String,
+		CreatedAt:   r.CreatedAt,
+	}
+}
@@ -6,8 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
 	"test-task/order-service/internal/storage"
+	"test-task/order-service/internal/storage/migrations"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
@@ -16,19 +21,68 @@ import (
 
 const dbDriver = "pgx"
 
-const initSchema = `
-CREATE TABLE IF NOT EXISTS orders (
-	id CHAR(19) PRIMARY KEY,
-	data JSONB NOT NULL,
-	UNIQUE (id, data)
-);
-`
+const (
+	qInsertOrder = `INSERT INTO orders (
+		id, track_number, entry, locale, internal_signature, customer_id,
+		delivery_service, shardkey, sm_id, date_created, oof_shard, status, data
+	) VALUES (
+		:id, :track_number, :entry, :locale, :internal_signature, :customer_id,
+		:delivery_service, :shardkey, :sm_id, :date_created, :oof_shard, :status, :data
+	) ON CONFLICT (id) DO NOTHING`
+
+	// the status is kept by the status lifecycle, not by the document
+	qSameOrderData = `SELECT data - 'status' = $2::jsonb - 'status' FROM orders WHERE id = $1`
+
+	qInsertConflict = `INSERT INTO order_conflicts (order_id, data) VALUES ($1, $2)`
+
+	qInsertDelivery = `INSERT INTO deliveries (
+		order_id, name, phone, zip, city, address, region, email
+	) VALUES (
+		:order_id, :name, :phone, :zip, :city, :address, :region, :email
+	)`
+
+	qInsertPayment = `INSERT INTO payments (
+		order_id, transaction, request_id, currency, provider, amount,
+		payment_dt, bank, delivery_cost, goods_total, custom_fee
+	) VALUES (
+		:order_id, :transaction, :request_id, :currency, :provider, :amount,
+		:payment_dt, :bank, :delivery_cost, :goods_total, :custom_fee
+	)`
+
+	qInsertItem = `INSERT INTO items (
+		order_id, chrt_id, track_number, price, rid, name,
+		sale, size, total_price, nm_id, brand, status
+	) VALUES (
+		:order_id, :chrt_id, :track_number, :price, :rid, :name,
+		:sale, :size, :total_price, :nm_id, :brand, :status
+	)`
+
+	qSelectOrder = `SELECT id, track_number, entry, locale, internal_signature, customer_id,
+		delivery_service, shardkey, sm_id, date_created, oof_shard, status
+		FROM orders WHERE id=$1`
+
+	qListOrders = `SELECT o.id, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id,
+		o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard, o.status
+		FROM orders o`
+
+	qSelectDeliveries = `SELECT order_id, name, phone, zip, city, address, region, email
+		FROM deliveries WHERE order_id = ANY($1)`
+
+	qSelectPayments = `SELECT order_id, transaction, request_id, currency, provider, amount,
+		payment_dt, bank, delivery_cost, goods_total, custom_fee
+		FROM payments WHERE order_id = ANY($1)`
+
+	qSelectItems = `SELECT order_id, chrt_id, track_number, price, rid, name,
+		sale, size, total_price, nm_id, brand, status
+		FROM items WHERE order_id = ANY($1) ORDER BY id`
+)
 
 type Storage struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	log *slog.Logger
 }
 
-func New(dbUri string) (*Storage, error) {
+func New(dbUri string, log *slog.Logger) (*Storage, error) {
 	const op = "storage.postgres.New"
 
 	db, err := sqlx.Open(dbDriver, dbUri)
@@ -37,68 +91,305 @@ func New(dbUri string) (*Storage, error) {
 	}
 
 	return &Storage{
-		db: db,
+		db:  db,
+		log: log,
 	}, nil
 }
 
+// InitDB brings the schema up to the latest migration
 func (s *Storage) InitDB(ctx context.Context) error {
 	const op = "storage.postgres.InitDB"
 
-	_, err := s.db.ExecContext(ctx, initSchema)
+	m, err := s.Migrator()
 	if err != nil {
-		return fmt.Errorf("%s: creating table: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
-func (s *Storage) Save(ctx context.Context, order domain.Order) error {
+func (s *Storage) Migrator() (*migrations.Migrator, error) {
+	return migrations.New(s.db.DB, s.log)
+}
+
+// Save writes the order into the normalized tables and keeps
+// the original document in orders.data, all in one transaction.
+// Saving an order again returns storage.ErrEntryAlreadyExists if the data
+// is the same, otherwise the new data is recorded in order_conflicts
+// and storage.ErrEntryConflict is returned
+func (s *Storage) Save(ctx context.Context, order domain.Order) (err error) {
 	const op = "storage.postgres.Save"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	q := `INSERT INTO orders (id, data) VALUES ($1, $2)`
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
 
-	stmt, err := s.db.PrepareContext(ctx, q)
+	data, err := json.Marshal(order)
 	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return fmt.Errorf("%s: marshalling order: %w", op, err)
 	}
 
-	if _, err := stmt.ExecContext(ctx, order.OrderUid, order); err != nil {
-		return fmt.Errorf("%s: saving entry: %w", op, err)
+	row := struct {
+		orderRow
+		Data []byte `db:"data"`
+	}{
+		orderRow: newOrderRow(order),
+		Data:     data,
+	}
+	if row.Status == "" {
+		row.Status = string(domain.StatusCreated)
 	}
 
+	res, err := tx.NamedExecContext(ctx, qInsertOrder, row)
+	if err != nil {
+		return fmt.Errorf("%s: saving order: %w", op, err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: saving order: %w", op, err)
+	}
+
+	if inserted == 0 {
+		err = s.resolveDuplicate(ctx, tx, order.OrderUid, data)
+		if errors.Is(err, storage.ErrEntryConflict) {
+			// keeping the recorded conflict
+			if cerr := tx.Commit(); cerr != nil {
+				return fmt.Errorf("%s: commit transaction: %w", op, cerr)
+			}
+		}
+		return err
+	}
+
+	if _, err = tx.NamedExecContext(ctx, qInsertDelivery, newDeliveryRow(order.OrderUid, order.Delivery)); err != nil {
+		return fmt.Errorf("%s: saving delivery: %w", op, err)
+	}
+
+	if _, err = tx.NamedExecContext(ctx, qInsertPayment, newPaymentRow(order.OrderUid, order.Payment)); err != nil {
+		return fmt.Errorf("%s: saving payment: %w", op, err)
+	}
+
+	for _, item := range order.Items {
+		if _, err = tx.NamedExecContext(ctx, qInsertItem, newItemRow(order.OrderUid, item)); err != nil {
+			return fmt.Errorf("%s: saving item: %w", op, err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, qInsertStatusChange, order.OrderUid, nil, row.Status, "", order.DateCreated); err != nil {
+		return fmt.Errorf("%s: saving initial status: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	s.log.DebugContext(ctx, "order inserted", slog.String("op", op), slog.String("order_uid", order.OrderUid))
+
 	return nil
 }
 
-func (s *Storage) Get(ctx context.Context, orderId string) (*domain.Order, error) {
-	const op = "storage.postgres.Get"
+// resolveDuplicate compares the data with the stored order, a conflicting
+// payload is recorded in order_conflicts within tx for investigation.
+// It returns either storage.ErrEntryAlreadyExists or storage.ErrEntryConflict,
+// unless the queries fail
+func (s *Storage) resolveDuplicate(ctx context.Context, tx *sqlx.Tx, orderId string, data []byte) error {
+	const op = "storage.postgres.resolveDuplicate"
 
-	q := `SELECT data FROM orders WHERE id=$1`
+	var same bool
+	if err := tx.GetContext(ctx, &same, qSameOrderData, orderId, data); err != nil {
+		return fmt.Errorf("%s: comparing with stored order: %w", op, err)
+	}
 
-	stmt, err := s.db.PrepareContext(ctx, q)
-	if err != nil {
-		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
+	if same {
+		return storage.ErrEntryAlreadyExists
 	}
 
-	var data []byte
+	if _, err := tx.ExecContext(ctx, qInsertConflict, orderId, data); err != nil {
+		return fmt.Errorf("%s: saving conflict: %w", op, err)
+	}
 
-	err = stmt.QueryRowContext(ctx, orderId).Scan(&data)
+	return storage.ErrEntryConflict
+}
 
+// Get reassembles the order from the normalized tables
+func (s *Storage) Get(ctx context.Context, orderId string) (*domain.Order, error) {
+	const op = "storage.postgres.Get"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var o orderRow
+
+	err := s.db.GetContext(ctx, &o, qSelectOrder, orderId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, storage.ErrEntryDoesntExists
 		}
 
-		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+		return nil, fmt.Errorf("%s: selecting order: %w", op, err)
+	}
+
+	orders, err := s.assemble(ctx, []orderRow{o})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.DebugContext(ctx, "order loaded", slog.String("op", op), slog.String("order_uid", orderId))
+
+	return &orders[0], nil
+}
+
+// List returns a page of orders matching the filter, newest first.
+// Pagination is keyset based on (date_created, id)
+func (s *Storage) List(ctx context.Context, filter storage.ListFilter) (*storage.OrderPage, error) {
+	const op = "storage.postgres.List"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = storage.DefaultListLimit
+	}
+	if limit > storage.MaxListLimit {
+		limit = storage.MaxListLimit
+	}
+
+	q := qListOrders
+
+	var (
+		conds []string
+		args  []any
+	)
+
+	where := func(cond string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+	}
+
+	if filter.PaymentProvider != "" {
+		q += ` JOIN payments p ON p.order_id = o.id`
+		where("p.provider = $%d", filter.PaymentProvider)
+	}
+	if filter.CustomerId != "" {
+		where("o.customer_id = $%d", filter.CustomerId)
+	}
+	if filter.TrackNumber != "" {
+		where("o.track_number = $%d", filter.TrackNumber)
+	}
+	if filter.DeliveryService != "" {
+		where("o.delivery_service = $%d", filter.DeliveryService)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("o.date_created >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("o.date_created < $%d", filter.CreatedTo)
+	}
+	if filter.After != nil {
+		where("(o.date_created, o.id) < ($%d, $%d)", filter.After.DateCreated, filter.After.OrderUid)
 	}
 
-	var order domain.Order
-	err = json.Unmarshal(data, &order)
+	if len(conds) > 0 {
+		q += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// one extra row tells whether there is a next page
+	args = append(args, limit+1)
+	q += fmt.Sprintf(" ORDER BY o.date_created DESC, o.id DESC LIMIT $%d", len(args))
+
+	var rows []orderRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("%s: selecting orders: %w", op, err)
+	}
+
+	page := &storage.OrderPage{}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = storage.Cursor{DateCreated: last.DateCreated, OrderUid: last.Id}.Encode()
+	}
 
+	orders, err := s.assemble(ctx, rows)
 	if err != nil {
-		return nil, fmt.Errorf("%s: unmarshalling data: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	page.Orders = orders
 
-	return &order, nil
+	return page, nil
+}
+
+// assemble loads deliveries, payments and items of the given orders
+// and builds domain orders keeping the rows order
+func (s *Storage) assemble(ctx context.Context, rows []orderRow) ([]domain.Order, error) {
+	orders := make([]domain.Order, 0, len(rows))
+	if len(rows) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]string, len(rows))
+	for i, r := range rows {
+		ids[i] = r.Id
+	}
+
+	var deliveries []deliveryRow
+	if err := s.db.SelectContext(ctx, &deliveries, qSelectDeliveries, ids); err != nil {
+		return nil, fmt.Errorf("selecting deliveries: %w", err)
+	}
+
+	var payments []paymentRow
+	if err := s.db.SelectContext(ctx, &payments, qSelectPayments, ids); err != nil {
+		return nil, fmt.Errorf("selecting payments: %w", err)
+	}
+
+	var items []itemRow
+	if err := s.db.SelectContext(ctx, &items, qSelectItems, ids); err != nil {
+		return nil, fmt.Errorf("selecting items: %w", err)
+	}
+
+	deliveryByOrder := make(map[string]deliveryRow, len(deliveries))
+	for _, d := range deliveries {
+		deliveryByOrder[d.OrderId] = d
+	}
+
+	paymentByOrder := make(map[string]paymentRow, len(payments))
+	for _, p := range payments {
+		paymentByOrder[p.OrderId] = p
+	}
+
+	itemsByOrder := make(map[string][]itemRow, len(rows))
+	for _, i := range items {
+		itemsByOrder[i.OrderId] = append(itemsByOrder[i.OrderId], i)
+	}
+
+	for _, r := range rows {
+		orders = append(orders, *r.toDomain(deliveryByOrder[r.Id], paymentByOrder[r.Id], itemsByOrder[r.Id]))
+	}
+
+	return orders, nil
+}
+
+// Ping checks a connection from the pool is usable
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
 func (s *Storage) Close() error {
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/metrics"
+	"test-task/order-service/internal/storage"
+	"time"
+)
+
+const (
+	qSelectStatus = `SELECT status FROM orders WHERE id = $1`
+
+	qUpdateStatus = `UPDATE orders SET status = $3 WHERE id = $1 AND status = $2`
+
+	qInsertStatusChange = `INSERT INTO order_status_history (order_id, from_status, to_status, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	qSelectStatusHistory = `SELECT order_id, COALESCE(from_status, '') AS from_status, to_status, reason, changed_at
+		FROM order_status_history WHERE order_id = $1 ORDER BY id`
+)
+
+func (s *Storage) GetStatus(ctx context.Context, orderId string) (domain.OrderStatus, error) {
+	const op = "storage.postgres.GetStatus"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var status string
+
+	err := s.db.GetContext(ctx, &status, qSelectStatus, orderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", storage.ErrEntryDoesntExists
+		}
+
+		return "", fmt.Errorf("%s: selecting status: %w", op, err)
+	}
+
+	return domain.OrderStatus(status), nil
+}
+
+// UpdateStatus moves the order from change.From to change.To and appends the history entry.
+// storage.ErrStatusChanged is returned if the order is no longer in change.From
+func (s *Storage) UpdateStatus(ctx context.Context, change domain.StatusChange) (err error) {
+	const op = "storage.postgres.UpdateStatus"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, qUpdateStatus, change.OrderUid, change.From, change.To)
+	if err != nil {
+		return fmt.Errorf("%s: updating status: %w", op, err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: updating status: %w", op, err)
+	}
+
+	if updated == 0 {
+		var exists bool
+		if err = tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, change.OrderUid); err != nil {
+			return fmt.Errorf("%s: checking order: %w", op, err)
+		}
+
+		if !exists {
+			err = storage.ErrEntryDoesntExists
+			return err
+		}
+
+		err = storage.ErrStatusChanged
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, qInsertStatusChange,
+		change.OrderUid, change.From, change.To, change.Reason, change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("%s: saving history: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) StatusHistory(ctx context.Context, orderId string) ([]domain.StatusChange, error) {
+	const op = "storage.postgres.StatusHistory"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var rows []statusChangeRow
+	if err := s.db.SelectContext(ctx, &rows, qSelectStatusHistory, orderId); err != nil {
+		return nil, fmt.Errorf("%s: selecting history: %w", op, err)
+	}
+
+	// every stored order has at least the initial entry
+	if len(rows) == 0 {
+		return nil, storage.ErrEntryDoesntExists
+	}
+
+	res := make([]domain.StatusChange, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, r.toDomain())
+	}
+
+	return res, nil
+}
@@ -2,16 +2,117 @@ package storage
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"test-task/order-service/internal/domain"
+	"time"
 )
 
 type Storage interface {
 	Save(ctx context.Context, order domain.Order) error
+	// SaveBatch saves the orders in one transaction, returning
+	// the error Save would return for each of them
+	SaveBatch(ctx context.Context, orders []domain.Order) ([]error, error)
 	Get(ctx context.Context, orderId string) (*domain.Order, error)
+	List(ctx context.Context, filter ListFilter) (*OrderPage, error)
+
+	GetStatus(ctx context.Context, orderId string) (domain.OrderStatus, error)
+	// UpdateStatus applies the change only if the order is still in change.From
+	UpdateStatus(ctx context.Context, change domain.StatusChange) error
+	StatusHistory(ctx context.Context, orderId string) ([]domain.StatusChange, error)
+}
+
+// DeadLetterStorage keeps messages that failed processing until they are replayed
+type DeadLetterStorage interface {
+	SaveDeadLetter(ctx context.Context, dl *domain.DeadLetter) error
+	ListDeadLetters(ctx context.Context, afterId int64, limit int) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, id int64) error
+}
+
+// AccessStatsStorage keeps how often each order is looked up
+type AccessStatsStorage interface {
+	// RecordAccesses adds the counts to the totals of the orders
+	RecordAccesses(ctx context.Context, counts map[string]int64) error
+	// MostAccessed returns the ids of up to limit orders with the highest totals
+	MostAccessed(ctx context.Context, limit int) ([]string, error)
+	// PurgeAccesses deletes the totals of the orders not looked up for olderThan
+	PurgeAccesses(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// IdempotencyStorage keeps the idempotency keys of the requests creating orders
+type IdempotencyStorage interface {
+	// ClaimIdempotencyKey records the key for the request with the hash.
+	// If the key has been claimed already, the recorded key is returned
+	// along with ErrEntryAlreadyExists, unless its request is still in
+	// progress after staleAfter, then it is claimed again
+	ClaimIdempotencyKey(ctx context.Context, key string, requestHash string, staleAfter time.Duration) (*domain.IdempotencyKey, error)
+	// CompleteIdempotencyKey marks the request with the key as done, creating the order
+	CompleteIdempotencyKey(ctx context.Context, key string, orderId string) error
+	// ReleaseIdempotencyKey forgets the key of a request that failed,
+	// so the request can be retried with it
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	// PurgeIdempotencyKeys deletes the keys claimed more than olderThan ago,
+	// returning their number
+	PurgeIdempotencyKeys(ctx context.Context, olderThan time.Duration) (int64, error)
 }
 
 var (
 	ErrEntryAlreadyExists = fmt.Errorf("entry already exists")
 	ErrEntryDoesntExists  = fmt.Errorf("entry doesn't exists")
+	ErrInvalidCursor      = fmt.Errorf("invalid cursor")
+	ErrStatusChanged      = fmt.Errorf("status has been changed concurrently")
+
+	// ErrEntryConflict is returned when an entry with the same id
+	// but different data already exists, it also matches ErrEntryAlreadyExists
+	ErrEntryConflict = fmt.Errorf("%w with different data", ErrEntryAlreadyExists)
 )
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 1000
+)
+
+// ListFilter narrows down the orders returned by List, zero values are ignored
+type ListFilter struct {
+	CustomerId      string
+	TrackNumber     string
+	DeliveryService string
+	PaymentProvider string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	After           *Cursor
+	Limit           int
+}
+
+// OrderPage is a single page of orders sorted by date_created, newest first
+type OrderPage struct {
+	Orders     []domain.Order `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// Cursor points at the last order of a page, the next page starts right after it
+type Cursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUid    string    `json:"u"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.OrderUid == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/warmup/tracker.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/warmup/tracker.go -destination=internal/warmup/mocks/access_recorder.go
+//
+
+// Package mock_warmup is a generated GoMock package.
+package mock_warmup
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccessRecorder is a mock of AccessRecorder interface.
+type MockAccessRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessRecorderMockRecorder
+}
+
+// MockAccessRecorderMockRecorder is the mock recorder for MockAccessRecorder.
+type MockAccessRecorderMockRecorder struct {
+	mock *MockAccessRecorder
+}
+
+// NewMockAccessRecorder creates a new mock instance.
+func NewMockAccessRecorder(ctrl *gomock.Controller) *MockAccessRecorder {
+	mock := &MockAccessRecorder{ctrl: ctrl}
+	mock.recorder = &MockAccessRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessRecorder) EXPECT() *MockAccessRecorderMockRecorder {
+	return m.recorder
+}
+
+// PurgeAccesses mocks base method.
+func (m *MockAccessRecorder) PurgeAccesses(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAccesses", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeAccesses indicates an expected call of PurgeAccesses.
+func (mr *MockAccessRecorderMockRecorder) PurgeAccesses(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAccesses", reflect.TypeOf((*MockAccessRecorder)(nil).PurgeAccesses), ctx, olderThan)
+}
+
+// RecordAccesses mocks base method.
+func (m *MockAccessRecorder) RecordAccesses(ctx context.Context, counts map[string]int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAccesses", ctx, counts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAccesses indicates an expected call of RecordAccesses.
+func (mr *MockAccessRecorderMockRecorder) RecordAccesses(ctx, counts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAccesses", reflect.TypeOf((*MockAccessRecorder)(nil).RecordAccesses), ctx, counts)
+}
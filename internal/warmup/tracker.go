@@ -0,0 +1,119 @@
+package warmup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"time"
+)
+
+// maxPending bounds the distinct orders counted between flushes,
+// lookups of further orders are not counted until the next flush
+const maxPending = 10000
+
+// AccessRecorder stores the lookup counts
+type AccessRecorder interface {
+	RecordAccesses(ctx context.Context, counts map[string]int64) error
+	PurgeAccesses(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Tracker wraps the cache of the read path, counting the lookups of each
+// order, and periodically adds the counts to the stored access stats
+// used by ModeFrequent. Only the lookups finding the order are counted,
+// the cache hits and the orders the read path adds after a miss, so
+// probing missing orders doesn't grow the stats. Stats of the orders
+// not looked up for the retention are purged
+type Tracker struct {
+	cache.Cache[string, *domain.Order]
+
+	recorder  AccessRecorder
+	retention time.Duration
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewTracker(c cache.Cache[string, *domain.Order], recorder AccessRecorder, retention time.Duration) *Tracker {
+	return &Tracker{
+		Cache:     c,
+		recorder:  recorder,
+		retention: retention,
+		counts:    make(map[string]int64),
+	}
+}
+
+func (t *Tracker) Get(key string) (*domain.Order, bool) {
+	order, ok := t.Cache.Get(key)
+	if ok {
+		t.count(key)
+	}
+
+	return order, ok
+}
+
+func (t *Tracker) Add(key string, order *domain.Order) bool {
+	t.count(key)
+
+	return t.Cache.Add(key, order)
+}
+
+func (t *Tracker) count(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, counted := t.counts[key]; counted || len(t.counts) < maxPending {
+		t.counts[key]++
+	}
+}
+
+// Flush stores the counts gathered since the previous flush.
+// Counts that fail to be stored are dropped
+func (t *Tracker) Flush(ctx context.Context) error {
+	const op = "warmup.Tracker.Flush"
+
+	t.mu.Lock()
+	counts := t.counts
+	t.counts = make(map[string]int64, len(counts))
+	t.mu.Unlock()
+
+	if err := t.recorder.RecordAccesses(ctx, counts); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Purge deletes the stats of the orders not looked up for the retention
+func (t *Tracker) Purge(ctx context.Context) (int64, error) {
+	const op = "warmup.Tracker.Purge"
+
+	purged, err := t.recorder.PurgeAccesses(ctx, t.retention)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return purged, nil
+}
+
+// Run flushes the counts and purges the old stats every interval until ctx is done
+func (t *Tracker) Run(ctx context.Context, log *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Error("failed recording access stats", slog.Any("error", err))
+			}
+			if _, err := t.Purge(ctx); err != nil {
+				log.Error("failed purging access stats", slog.Any("error", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/storage"
+)
+
+// Mode decides which orders are loaded into the cache on start
+type Mode string
+
+const (
+	// ModeSnapshot restores the cache snapshot, which is done by the
+	// cache itself, so warm-up does nothing more
+	ModeSnapshot Mode = "snapshot"
+	// ModeRecent loads the most recently created orders
+	ModeRecent Mode = "recent"
+	// ModeFrequent loads the orders looked up most often, as recorded by Tracker
+	ModeFrequent Mode = "frequent"
+	// ModeNone starts with the cache cold
+	ModeNone Mode = "none"
+)
+
+// Source provides the orders to warm the cache with
+type Source interface {
+	Get(ctx context.Context, orderId string) (*domain.Order, error)
+	List(ctx context.Context, filter storage.ListFilter) (*storage.OrderPage, error)
+	MostAccessed(ctx context.Context, limit int) ([]string, error)
+}
+
+type Warmer struct {
+	log    *slog.Logger
+	mode   Mode
+	orders int
+	source Source
+	cache  cache.Cache[string, *domain.Order]
+}
+
+// New creates a warmer loading up to orders orders chosen by mode into c
+func New(log *slog.Logger, mode Mode, orders int, source Source, c cache.Cache[string, *domain.Order]) (*Warmer, error) {
+	const op = "warmup.New"
+
+	switch mode {
+	case ModeSnapshot, ModeRecent, ModeFrequent, ModeNone:
+	default:
+		return nil, fmt.Errorf("%s: unknown warm-up mode %q", op, mode)
+	}
+
+	return &Warmer{
+		log:    log,
+		mode:   mode,
+		orders: orders,
+		source: source,
+		cache:  c,
+	}, nil
+}
+
+// Run loads the orders, returning how many were added to the cache.
+// Orders loaded before an error stay cached
+func (w *Warmer) Run(ctx context.Context) (int, error) {
+	const op = "warmup.Run"
+
+	var (
+		n   int
+		err error
+	)
+
+	switch w.mode {
+	case ModeRecent:
+		n, err = w.recent(ctx)
+	case ModeFrequent:
+		n, err = w.frequent(ctx)
+	}
+
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+func (w *Warmer) recent(ctx context.Context) (int, error) {
+	loaded := 0
+	filter := storage.ListFilter{}
+
+	for loaded < w.orders {
+		filter.Limit = min(w.orders-loaded, storage.MaxListLimit)
+
+		page, err := w.source.List(ctx, filter)
+		if err != nil {
+			return loaded, fmt.Errorf("listing orders: %w", err)
+		}
+
+		for i := range page.Orders {
+			order := page.Orders[i]
+			if w.cache.Add(order.OrderUid, &order) {
+				loaded++
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		if filter.After, err = storage.DecodeCursor(page.NextCursor); err != nil {
+			return loaded, fmt.Errorf("decoding cursor: %w", err)
+		}
+	}
+
+	return loaded, nil
+}
+
+func (w *Warmer) frequent(ctx context.Context) (int, error) {
+	ids, err := w.source.MostAccessed(ctx, w.orders)
+	if err != nil {
+		return 0, fmt.Errorf("getting access stats: %w", err)
+	}
+
+	loaded := 0
+	for _, id := range ids {
+		order, err := w.source.Get(ctx, id)
+
+		// lookups of orders that never existed are counted as well
+		if errors.Is(err, storage.ErrEntryDoesntExists) {
+			continue
+		}
+		if err != nil {
+			return loaded, fmt.Errorf("getting order %s: %w", id, err)
+		}
+
+		if w.cache.Add(id, order) {
+			loaded++
+		}
+	}
+
+	w.log.Debug("warmed up from access stats", slog.Int("candidates", len(ids)), slog.Int("loaded", loaded))
+
+	return loaded, nil
+}
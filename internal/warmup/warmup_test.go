@@ -0,0 +1,194 @@
+package warmup_test
+
+import (
+	"context"
+	"errors"
+	"test-task/order-service/internal/cache"
+	"test-task/order-service/internal/domain"
+	"test-task/order-service/internal/logging"
+	"test-task/order-service/internal/storage"
+	"test-task/order-service/internal/warmup"
+	mock_warmup "test-task/order-service/internal/warmup/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Warmer(t *testing.T) {
+	cursor := storage.Cursor{
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OrderUid:    "b563feb7b2b84b64c8w",
+	}
+
+	test_cases := []struct {
+		test_name string
+		mode      warmup.Mode
+		orders    int
+		prepare   func(m *mock_warmup.MockSource)
+		wantKeys  []string
+		wantErr   bool
+	}{
+		{
+			test_name: "Recent orders across pages",
+			mode:      warmup.ModeRecent,
+			orders:    3,
+			prepare: func(m *mock_warmup.MockSource) {
+				gomock.InOrder(
+					m.EXPECT().List(gomock.Any(), storage.ListFilter{Limit: 3}).Return(&storage.OrderPage{
+						Orders:     []domain.Order{{OrderUid: "9650f7fa5b404c2f996"}, {OrderUid: "b563feb7b2b84b64c8w"}},
+						NextCursor: cursor.Encode(),
+					}, nil),
+					m.EXPECT().List(gomock.Any(), storage.ListFilter{Limit: 1, After: &cursor}).Return(&storage.OrderPage{
+						Orders: []domain.Order{{OrderUid: "a563feb7b2b84b64c8w"}},
+					}, nil),
+				)
+			},
+			wantKeys: []string{"9650f7fa5b404c2f996", "b563feb7b2b84b64c8w", "a563feb7b2b84b64c8w"},
+		},
+		{
+			test_name: "Fewer recent orders than requested",
+			mode:      warmup.ModeRecent,
+			orders:    10,
+			prepare: func(m *mock_warmup.MockSource) {
+				m.EXPECT().List(gomock.Any(), storage.ListFilter{Limit: 10}).Return(&storage.OrderPage{
+					Orders: []domain.Order{{OrderUid: "9650f7fa5b404c2f996"}},
+				}, nil)
+			},
+			wantKeys: []string{"9650f7fa5b404c2f996"},
+		},
+		{
+			test_name: "Frequent orders skip missing ones",
+			mode:      warmup.ModeFrequent,
+			orders:    3,
+			prepare: func(m *mock_warmup.MockSource) {
+				m.EXPECT().MostAccessed(gomock.Any(), 3).
+					Return([]string{"9650f7fa5b404c2f996", "9650f7fa5b404c2f999"}, nil)
+				m.EXPECT().Get(gomock.Any(), "9650f7fa5b404c2f996").
+					Return(&domain.Order{OrderUid: "9650f7fa5b404c2f996"}, nil)
+				m.EXPECT().Get(gomock.Any(), "9650f7fa5b404c2f999").
+					Return(nil, storage.ErrEntryDoesntExists)
+			},
+			wantKeys: []string{"9650f7fa5b404c2f996"},
+		},
+		{
+			test_name: "Access stats unavailable",
+			mode:      warmup.ModeFrequent,
+			orders:    3,
+			prepare: func(m *mock_warmup.MockSource) {
+				m.EXPECT().MostAccessed(gomock.Any(), 3).Return(nil, errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			test_name: "Cold start",
+			mode:      warmup.ModeNone,
+			orders:    3,
+		},
+		{
+			test_name: "Snapshot is restored by the cache",
+			mode:      warmup.ModeSnapshot,
+			orders:    3,
+		},
+	}
+
+	for i := range test_cases {
+		tc := test_cases[i]
+
+		t.Run(tc.test_name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			source := mock_warmup.NewMockSource(ctrl)
+			if tc.prepare != nil {
+				tc.prepare(source)
+			}
+
+			c := cache.New[string, *domain.Order](10)
+
+			w, err := warmup.New(logging.Discard(), tc.mode, tc.orders, source, c)
+			assert.NoError(t, err)
+
+			n, err := w.Run(context.Background())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.Equal(t, len(tc.wantKeys), n)
+			assert.Equal(t, len(tc.wantKeys), c.Len())
+			for _, key := range tc.wantKeys {
+				order, ok := c.Get(key)
+				assert.True(t, ok, key)
+				assert.Equal(t, key, order.OrderUid)
+			}
+		})
+	}
+}
+
+func Test_UnknownMode(t *testing.T) {
+	_, err := warmup.New(logging.Discard(), "popular", 3, nil, cache.New[string, *domain.Order](10))
+	assert.Error(t, err)
+}
+
+func Test_Tracker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := mock_warmup.NewMockAccessRecorder(ctrl)
+
+	c := cache.New[string, *domain.Order](10)
+	c.Add("9650f7fa5b404c2f996", &domain.Order{OrderUid: "9650f7fa5b404c2f996"})
+
+	tracker := warmup.NewTracker(c, recorder, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, ok := tracker.Get("9650f7fa5b404c2f996")
+		assert.True(t, ok)
+	}
+
+	// a miss isn't counted, only the order found in storage after it
+	_, ok := tracker.Get("b563feb7b2b84b64c8w")
+	assert.False(t, ok)
+	tracker.Add("b563feb7b2b84b64c8w", &domain.Order{OrderUid: "b563feb7b2b84b64c8w"})
+
+	// missing orders aren't counted at all
+	tracker.Get("9650f7fa5b404c2f999")
+
+	gomock.InOrder(
+		recorder.EXPECT().RecordAccesses(gomock.Any(), map[string]int64{
+			"9650f7fa5b404c2f996": 3,
+			"b563feb7b2b84b64c8w": 1,
+		}).Return(nil),
+		recorder.EXPECT().RecordAccesses(gomock.Any(), map[string]int64{}).Return(nil),
+	)
+
+	assert.NoError(t, tracker.Flush(context.Background()))
+
+	// counts start over after a flush
+	assert.NoError(t, tracker.Flush(context.Background()))
+}
+
+func Test_TrackerPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := mock_warmup.NewMockAccessRecorder(ctrl)
+	gomock.InOrder(
+		recorder.EXPECT().PurgeAccesses(gomock.Any(), 30*24*time.Hour).Return(int64(2), nil),
+		recorder.EXPECT().PurgeAccesses(gomock.Any(), 30*24*time.Hour).Return(int64(0), errors.New("connection refused")),
+	)
+
+	tracker := warmup.NewTracker(cache.New[string, *domain.Order](10), recorder, 30*24*time.Hour)
+
+	purged, err := tracker.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	_, err = tracker.Purge(context.Background())
+	assert.Error(t, err)
+}